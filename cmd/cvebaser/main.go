@@ -4,7 +4,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"time"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/cvebase/cvebaser"
 	"github.com/cvebase/cvebaser/export"
@@ -16,12 +21,36 @@ func main() {
 	cli.Main(cli.Commands{
 		"lint":   new(lintCommand),
 		"export": new(exportCommand),
+		"dedupe": new(dedupeCommand),
+		"hook": cli.Commands{
+			"install": new(hookInstallCommand),
+		},
 	})
 }
 
 type lintCommand struct {
-	commit   string
-	repoPath string
+	commit      string
+	commitRange string
+	since       string
+	mergeBase   string
+	staged      bool
+	worktree    bool
+	repoPath    string
+	configPath  string
+	format      string
+	check       bool
+	diff        bool
+	fixPaths    bool
+	scaffold    bool
+	strict      bool
+	listRules   bool
+	enable      string
+	disable     string
+	statsFile   string
+	failOn      string
+	jobs        int
+	cacheDir    string
+	noCache     bool
 }
 
 func (cmd *lintCommand) DefineFlags(fs *flag.FlagSet) {
@@ -29,38 +58,449 @@ func (cmd *lintCommand) DefineFlags(fs *flag.FlagSet) {
 		"c", cmd.commit,
 		"commit hash",
 	)
+	fs.StringVar(&cmd.commitRange,
+		"range", cmd.commitRange,
+		"commit range to lint files from e.g. base..head or base...head for merge-base",
+	)
+	fs.StringVar(&cmd.since,
+		"since", cmd.since,
+		"lint files changed by commits since ref, up to HEAD",
+	)
+	fs.StringVar(&cmd.mergeBase,
+		"merge-base", cmd.mergeBase,
+		"lint files changed since the merge-base of HEAD and the given branch",
+	)
+	fs.BoolVar(&cmd.staged,
+		"staged", cmd.staged,
+		"lint the staged content of files for the next commit, without writing them",
+	)
+	fs.BoolVar(&cmd.worktree,
+		"worktree", cmd.worktree,
+		"lint files with uncommitted changes, staged or not",
+	)
 	fs.StringVar(&cmd.repoPath,
 		"r", cmd.repoPath,
 		"path to cvebase.com repo",
 	)
-	// TODO add concurrency option
+	fs.StringVar(&cmd.configPath,
+		"config", cmd.configPath,
+		"path to lint config file (default <repo>/"+lint.DefaultConfigFile+")",
+	)
+	fs.StringVar(&cmd.format,
+		"format", cmd.format,
+		"output format: "+strings.Join(lint.Formats, ", ")+" (default text)",
+	)
+	fs.BoolVar(&cmd.check,
+		"check", cmd.check,
+		"report files that would be changed without writing them",
+	)
+	fs.BoolVar(&cmd.diff,
+		"diff", cmd.diff,
+		"print a unified diff of changes without writing them",
+	)
+	fs.BoolVar(&cmd.fixPaths,
+		"fix-paths", cmd.fixPaths,
+		"move misplaced cve and researcher files to their correct directory",
+	)
+	fs.BoolVar(&cmd.scaffold,
+		"scaffold", cmd.scaffold,
+		"create stub cve files for CVEs listed by researchers that have no cve file; stubs fail cve-required until filled in",
+	)
+	fs.BoolVar(&cmd.strict,
+		"strict", cmd.strict,
+		"report unknown front matter keys",
+	)
+	fs.BoolVar(&cmd.listRules,
+		"list-rules", cmd.listRules,
+		"list available lint rules and exit",
+	)
+	fs.StringVar(&cmd.enable,
+		"enable", cmd.enable,
+		"comma-separated lint rule IDs to enable",
+	)
+	fs.StringVar(&cmd.disable,
+		"disable", cmd.disable,
+		"comma-separated lint rule IDs to disable",
+	)
+	fs.StringVar(&cmd.failOn,
+		"fail-on", "error",
+		"exit 1 if there are findings at or above severity: warning, error",
+	)
+	fs.StringVar(&cmd.cacheDir,
+		"cache-dir", cmd.cacheDir,
+		"lint cache dir (default <git dir>/"+lint.DefaultCacheDir+")",
+	)
+	fs.BoolVar(&cmd.noCache,
+		"no-cache", cmd.noCache,
+		"lint every file, ignoring the lint cache",
+	)
+	fs.StringVar(&cmd.statsFile,
+		"stats", cmd.statsFile,
+		"write lint stats as JSON to file",
+	)
+	fs.IntVar(&cmd.jobs,
+		"j", cmd.jobs,
+		fmt.Sprintf("number of files to lint concurrently (default %d, or workers from config)", lint.DefaultWorkers),
+	)
 }
 
-func (cmd *lintCommand) Run(_ context.Context, _ []string) error {
-	repo, err := cvebaser.NewRepo(cmd.repoPath, &cvebaser.GitOpts{})
+// Exit codes of the lint command
+const (
+	exitFindings = 1 // findings at or above the -fail-on severity
+	exitFailure  = 2 // operational failure e.g. git or file walk error
+)
+
+func (cmd *lintCommand) Run(ctx context.Context, _ []string) error {
+	failOn, err := lint.ParseSeverity(cmd.failOn)
+	if err != nil {
+		return exit(ctx, exitFailure, "invalid -fail-on: %v", err)
+	}
+
+	c, err := cmd.run(ctx)
 	if err != nil {
+		return exit(ctx, exitFailure, "%v", err)
+	}
+	if c == nil {
+		return nil
+	}
+	if n := c.CountAtLeast(failOn); n > 0 {
+		return exit(ctx, exitFindings, "%d findings at or above %s severity", n, failOn)
+	}
+	return nil
+}
+
+// exit writes the message to stderr and exits with code.
+// cli.Exitf would print the message to stdout, mixing it into the lint output.
+// An interrupted run is left to cli, which exits with its own code.
+func exit(ctx context.Context, code int, format string, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
-	linter := &lint.Linter{Repo: repo}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(code)
+	return nil
+}
+
+// run lints the repo, writing findings to stdout.
+// The returned collector is nil when no files were linted e.g. -list-rules.
+func (cmd *lintCommand) run(ctx context.Context) (*lint.Collector, error) {
+	formatter, err := lint.NewFormatter(cmd.format)
+	if err != nil {
+		return nil, err
+	}
 
+	linter := &lint.Linter{
+		Check:        cmd.check,
+		Diff:         cmd.diff,
+		FixPaths:     cmd.fixPaths,
+		ScaffoldCVEs: cmd.scaffold,
+		Rules:        lint.DefaultRegistry(),
+	}
+
+	// Rules can be listed without a repo, in which case no config is loaded
+	cfg := &lint.Config{}
+	if cmd.repoPath != "" || !cmd.listRules {
+		repo, err := cvebaser.NewRepo(cmd.repoPath, &cvebaser.GitOpts{})
+		if err != nil {
+			return nil, err
+		}
+		linter.Repo = repo
+		cfg, err = lint.LoadRepoConfig(repo, cmd.configPath)
+		if err != nil {
+			return nil, err
+		}
+	} else if cmd.configPath != "" {
+		var err error
+		cfg, err = lint.LoadConfig(cmd.configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = cfg.Apply(linter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Flags take precedence over config
+	if cmd.strict {
+		linter.Rules.Enable(lint.RuleUnknownKey)
+	}
+	err = setRules(linter.Rules.Enable, cmd.enable)
+	if err != nil {
+		return nil, err
+	}
+	err = setRules(linter.Rules.Disable, cmd.disable)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.listRules {
+		printRules(linter.Rules)
+		return nil, nil
+	}
+
+	if !cmd.noCache {
+		// The cache only speeds up linting, so lint without it if it can't be opened
+		linter.Cache, err = cmd.openCache(linter.Repo, cfg, linter.Rules)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: lint cache disabled: %v\n", err)
+		}
+	}
+
+	var c *lint.Collector
 	linter.Start()
-	if cmd.commit != "" {
-		err = linter.LintCommit(cmd.commit)
+	switch {
+	case cmd.commit != "":
+		c, err = linter.LintCommit(ctx, cmd.commit)
+	case cmd.staged:
+		c, err = linter.LintStaged(ctx)
+	case cmd.worktree:
+		var files []string
+		files, err = linter.WorktreeFilenames()
+		if err == nil {
+			c, err = linter.LintFiles(ctx, files)
+		}
+	case cmd.commitRange != "" || cmd.since != "" || cmd.mergeBase != "":
+		var base, head string
+		base, head, err = cmd.resolveRange(linter.Repo)
+		if err == nil {
+			c, err = linter.LintRange(ctx, base, head)
+		}
+	default:
+		workers := cfg.WorkerCount()
+		if cmd.jobs > 0 {
+			workers = cmd.jobs
+		}
+		c, err = linter.LintAll(ctx, workers)
+	}
+	linter.End(c)
+	// Cached results are valid even if linting stopped early. The cache isn't
+	// saved by read-only runs, and failing to save it doesn't fail the run.
+	if !cmd.check && !cmd.diff {
+		if cerr := linter.Cache.Save(); cerr != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", cerr)
+		}
+	}
+	if c != nil {
+		// Diffs are only mixed into plain text output
+		if cmd.diff && (cmd.format == "" || cmd.format == "text") {
+			for _, d := range c.Diffs() {
+				fmt.Print(d.Diff)
+			}
+		}
+		ferr := formatter.Format(os.Stdout, c, linter.Rules)
+		if ferr != nil {
+			return c, ferr
+		}
+	}
+	if err != nil {
+		return c, err
+	}
+
+	// Keep stdout machine readable for structured formats
+	fmt.Fprintln(os.Stderr)
+	err = linter.Stats.WriteSummary(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.statsFile != "" {
+		err = writeStats(cmd.statsFile, linter.Stats)
 		if err != nil {
-			return err
+			return c, err
+		}
+	}
+
+	return c, nil
+}
+
+// openCache opens the lint cache from the -cache-dir flag, config or default dir
+func (cmd *lintCommand) openCache(repo *cvebaser.Repo, cfg *lint.Config, reg *lint.Registry) (*lint.Cache, error) {
+	dir := cmd.cacheDir
+	switch {
+	case dir != "":
+	case cfg.CacheDir != "":
+		dir = cfg.CacheDir
+		if !filepath.IsAbs(dir) {
+			dir = repo.GetFullPath(dir)
 		}
-	} else {
-		err = linter.LintAll(20)
+	default:
+		var err error
+		dir, err = lint.RepoCacheDir(repo)
 		if err != nil {
+			return nil, err
+		}
+	}
+	key, err := lint.CacheKey(cfg, reg)
+	if err != nil {
+		return nil, err
+	}
+	return lint.OpenCache(dir, key)
+}
+
+// resolveRange returns the base and head revisions to lint from the
+// -range, -since or -merge-base flags
+func (cmd *lintCommand) resolveRange(repo *cvebaser.Repo) (base, head string, err error) {
+	switch {
+	case cmd.commitRange != "":
+		// base...head lints changes since head diverged from base
+		if sp := strings.SplitN(cmd.commitRange, "...", 2); len(sp) == 2 {
+			base, head = sp[0], sp[1]
+			if head == "" {
+				head = "HEAD"
+			}
+			base, err = repo.MergeBase(base, head)
+			return base, head, err
+		}
+		sp := strings.SplitN(cmd.commitRange, "..", 2)
+		if len(sp) != 2 || sp[0] == "" {
+			return "", "", fmt.Errorf("invalid range %q: want base..head", cmd.commitRange)
+		}
+		base, head = sp[0], sp[1]
+		if head == "" {
+			head = "HEAD"
+		}
+		return base, head, nil
+	case cmd.since != "":
+		return cmd.since, "HEAD", nil
+	default:
+		base, err = repo.MergeBase(cmd.mergeBase, "HEAD")
+		return base, "HEAD", err
+	}
+}
+
+// writeStats writes lint stats as JSON to file p
+func writeStats(p string, stats *lint.Stats) error {
+	f, err := os.Create(p)
+	if err != nil {
+		return fmt.Errorf("error creating stats file: %v", err)
+	}
+	defer f.Close()
+	err = stats.WriteJSON(f)
+	if err != nil {
+		return fmt.Errorf("error writing stats file: %v", err)
+	}
+	return nil
+}
+
+// setRules applies fn e.g. Registry.Enable to each rule ID in comma-separated list ids
+func setRules(fn func(string) error, ids string) error {
+	for _, id := range strings.Split(ids, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if err := fn(id); err != nil {
 			return err
 		}
 	}
-	linter.End()
+	return nil
+}
+
+// printRules writes a table of registered rules to stdout
+func printRules(rules *lint.Registry) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tENABLED\tSEVERITY\tDESCRIPTION")
+	for _, rule := range rules.Rules() {
+		fmt.Fprintf(tw, "%s\t%t\t%s\t%s\n", rule.ID(), rules.Enabled(rule.ID()), rules.Severity(rule), rule.Description())
+	}
+	tw.Flush()
+}
+
+// preCommitHook lints staged files before each commit
+const preCommitHook = `#!/bin/sh
+# Installed by cvebaser hook install
+exec cvebaser lint -staged -check -r "$(git rev-parse --show-toplevel)"
+`
+
+type hookInstallCommand struct {
+	repoPath string
+	force    bool
+}
+
+func (cmd *hookInstallCommand) DefineFlags(fs *flag.FlagSet) {
+	fs.StringVar(&cmd.repoPath,
+		"r", cmd.repoPath,
+		"path to cvebase.com repo",
+	)
+	fs.BoolVar(&cmd.force,
+		"f", cmd.force,
+		"overwrite an existing pre-commit hook",
+	)
+}
 
-	// TODO print number of files modified
+func (cmd *hookInstallCommand) Run(_ context.Context, _ []string) error {
+	repo, err := cvebaser.NewRepo(cmd.repoPath, &cvebaser.GitOpts{})
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("\nTime Completed: %v\n", linter.Stats.Duration().Round(time.Second))
+	hookPath, err := repo.HookPath("pre-commit")
+	if err != nil {
+		return err
+	}
+	exists, err := cvebaser.Exists(hookPath)
+	if err != nil {
+		return err
+	}
+	if exists && !cmd.force {
+		return fmt.Errorf("pre-commit hook already exists: %s (use -f to overwrite)", hookPath)
+	}
+
+	err = os.MkdirAll(path.Dir(hookPath), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating hooks dir: %v", err)
+	}
+	err = ioutil.WriteFile(hookPath, []byte(preCommitHook), 0755)
+	if err != nil {
+		return fmt.Errorf("error writing pre-commit hook: %v", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	err = os.Chmod(hookPath, 0755)
+	if err != nil {
+		return fmt.Errorf("error making pre-commit hook executable: %v", err)
+	}
+
+	fmt.Printf("Installed pre-commit hook: %s\n", hookPath)
+	return nil
+}
+
+type dedupeCommand struct {
+	repoPath string
+	check    bool
+}
+
+func (cmd *dedupeCommand) DefineFlags(fs *flag.FlagSet) {
+	fs.StringVar(&cmd.repoPath,
+		"r", cmd.repoPath,
+		"path to cvebase.com repo",
+	)
+	fs.BoolVar(&cmd.check,
+		"check", cmd.check,
+		"list duplicate cve files without merging them",
+	)
+}
+
+func (cmd *dedupeCommand) Run(ctx context.Context, _ []string) error {
+	repo, err := cvebaser.NewRepo(cmd.repoPath, &cvebaser.GitOpts{})
+	if err != nil {
+		return err
+	}
+
+	dups, err := repo.DuplicateCVEs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, dup := range dups {
+		fmt.Printf("%s: %s -> %s\n", dup.CVEID, strings.Join(dup.Paths, ", "), dup.Path)
+		if cmd.check {
+			continue
+		}
+		err = repo.DedupeCVE(dup)
+		if err != nil {
+			return err
+		}
+	}
 
+	fmt.Fprintf(os.Stderr, "%d duplicate CVEs\n", len(dups))
 	return nil
 }
 
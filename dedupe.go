@@ -0,0 +1,182 @@
+package cvebaser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DuplicateCVE is a CVE ID with more than one cve file
+type DuplicateCVE struct {
+	CVEID string
+	// Path is where the cve file belongs e.g. cve/2020/14xxx/CVE-2020-14882.md
+	Path string
+	// Paths are the repo relative paths of every file for the CVE, sorted
+	Paths []string
+}
+
+// DuplicateCVEs groups cve files by normalized CVE ID, returning IDs with
+// more than one file sorted by ID. Files that fail to parse are skipped.
+// The filename is used for files with a missing or invalid id.
+func (r *Repo) DuplicateCVEs(ctx context.Context) ([]DuplicateCVE, error) {
+	paths, errStream := r.ScanTree(ctx.Done(), "cve", ".md")
+
+	ids := make(map[string]string)
+	for p := range paths {
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", p, err)
+		}
+		var cve CVE
+		if err = ParseMDFile(bytes.NewReader(content), &cve); err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(r.DirPath, p)
+		if err != nil {
+			return nil, err
+		}
+		ids[filepath.ToSlash(rel)] = cve.CVEID
+	}
+	if err := <-errStream; err != nil {
+		return nil, fmt.Errorf("error scanning cve files: %v", err)
+	}
+	return GroupDuplicateCVEs(ids), nil
+}
+
+// GroupDuplicateCVEs groups cve files by normalized CVE ID, returning IDs with
+// more than one file sorted by ID. ids maps repo relative paths of cve files to
+// the id in their front matter; the filename is used for a missing or invalid id.
+func GroupDuplicateCVEs(ids map[string]string) []DuplicateCVE {
+	groups := make(map[string][]string)
+	for p, id := range ids {
+		cveID, err := NormalizeCVEID(id)
+		if err != nil {
+			cveID, err = NormalizeCVEID(strings.TrimSuffix(path.Base(p), path.Ext(p)))
+			if err != nil {
+				continue
+			}
+		}
+		groups[cveID] = append(groups[cveID], p)
+	}
+
+	var dups []DuplicateCVE
+	for cveID, ps := range groups {
+		if len(ps) < 2 {
+			continue
+		}
+		cvePath, err := DocumentPath(&CVE{CVEID: cveID})
+		if err != nil {
+			continue
+		}
+		sort.Strings(ps)
+		dups = append(dups, DuplicateCVE{CVEID: cveID, Path: cvePath, Paths: ps})
+	}
+	sort.Slice(dups, func(i, j int) bool {
+		return dups[i].CVEID < dups[j].CVEID
+	})
+	return dups
+}
+
+// MergeCVE merges CVE b into a, taking the union of references and extra keys.
+// Differing advisories are concatenated, separated by a marker naming the file b came from.
+// Values of a take precedence for the ID and extra keys.
+func MergeCVE(a, b CVE, bPath string) CVE {
+	a.Pocs = SortUniqStrings(append(append([]string{}, a.Pocs...), b.Pocs...))
+	a.Writeups = SortUniqStrings(append(append([]string{}, a.Writeups...), b.Writeups...))
+	a.Courses = SortUniqStrings(append(append([]string{}, a.Courses...), b.Courses...))
+
+	aAdv, bAdv := strings.TrimSpace(a.Advisory), strings.TrimSpace(b.Advisory)
+	switch {
+	case bAdv == "" || aAdv == bAdv:
+	case aAdv == "":
+		a.Advisory = b.Advisory
+	default:
+		a.Advisory = fmt.Sprintf("%s\n\n<!-- merged from %s -->\n\n%s\n", aAdv, bPath, bAdv)
+	}
+
+	if len(b.Extra) > 0 {
+		extra := make(map[string]interface{}, len(a.Extra)+len(b.Extra))
+		for k, v := range b.Extra {
+			extra[k] = v
+		}
+		for k, v := range a.Extra {
+			extra[k] = v
+		}
+		a.Extra = extra
+	}
+	return a
+}
+
+// DedupeCVE merges every file of a duplicate CVE into the file at its correct path,
+// and removes the other files from the git worktree. The merged file is staged.
+// Refuses to overwrite a file at the correct path that isn't one of the duplicates,
+// e.g. because it failed to parse or its id is another CVE.
+func (r *Repo) DedupeCVE(dup DuplicateCVE) error {
+	// Merge into the file at the correct path if there is one,
+	// so its references and advisory come first
+	paths := make([]string, 0, len(dup.Paths))
+	for _, p := range dup.Paths {
+		if p == dup.Path {
+			paths = append([]string{p}, paths...)
+		} else {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) > 0 && paths[0] != dup.Path {
+		exists, err := Exists(r.GetFullPath(dup.Path))
+		if err != nil {
+			return fmt.Errorf("error checking file exists: %v", err)
+		}
+		if exists {
+			return fmt.Errorf("target already exists and is not a duplicate of %s: %s", dup.CVEID, dup.Path)
+		}
+	}
+
+	var merged CVE
+	for i, p := range paths {
+		content, err := ioutil.ReadFile(r.GetFullPath(p))
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", p, err)
+		}
+		var cve CVE
+		if err = ParseMDFile(bytes.NewReader(content), &cve); err != nil {
+			return fmt.Errorf("error parsing %s: %v", p, err)
+		}
+		if i == 0 {
+			merged = cve
+			continue
+		}
+		merged = MergeCVE(merged, cve, p)
+	}
+	merged.CVEID = dup.CVEID
+
+	// Write the merged file before removing any others, so nothing is lost on failure
+	err := os.MkdirAll(path.Dir(r.GetFullPath(dup.Path)), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating dir for %s: %v", dup.Path, err)
+	}
+	_, err = CompileToFile(r.GetFullPath(dup.Path), &merged)
+	if err != nil {
+		return err
+	}
+	err = r.StageFile(dup.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		if p == dup.Path {
+			continue
+		}
+		if err = r.RemoveFile(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
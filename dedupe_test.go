@@ -0,0 +1,104 @@
+package cvebaser
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeCVE(t *testing.T) {
+	a := CVE{
+		CVEID:    "CVE-2020-14882",
+		Pocs:     []string{"https://github.com/x/y"},
+		Advisory: "Oracle WebLogic RCE.\n",
+		Extra:    map[string]interface{}{"tags": "a"},
+	}
+	b := CVE{
+		CVEID:    "cve-2020-14882",
+		Pocs:     []string{"https://github.com/z/z", "https://github.com/x/y"},
+		Writeups: []string{"https://example.com/writeup"},
+		Advisory: "Other advisory.\n",
+		Extra:    map[string]interface{}{"tags": "b", "cwe": "CWE-94"},
+	}
+
+	got := MergeCVE(a, b, "cve/2020/0xxx/CVE-2020-14882.md")
+	assert.Equal(t, "CVE-2020-14882", got.CVEID)
+	assert.Equal(t, []string{"https://github.com/x/y", "https://github.com/z/z"}, got.Pocs)
+	assert.Equal(t, []string{"https://example.com/writeup"}, got.Writeups)
+	assert.Equal(t, "Oracle WebLogic RCE.\n\n<!-- merged from cve/2020/0xxx/CVE-2020-14882.md -->\n\nOther advisory.\n", got.Advisory)
+	assert.Equal(t, map[string]interface{}{"tags": "a", "cwe": "CWE-94"}, got.Extra)
+
+	// Matching advisories aren't repeated
+	b.Advisory = a.Advisory
+	assert.Equal(t, a.Advisory, MergeCVE(a, b, "").Advisory)
+}
+
+func TestRepo_DedupeCVE(t *testing.T) {
+	tr := newTestGitRepo(t)
+	defer os.RemoveAll(tr.dir)
+	dir := tr.dir
+
+	files := map[string]string{
+		"cve/2020/14xxx/CVE-2020-14882.md": "---\nid: CVE-2020-14882\npocs:\n  - https://github.com/x/y\n---\n",
+		"cve/2020/0xxx/CVE-2020-14882.md":  "---\nid: cve-2020-14882\npocs:\n  - https://github.com/z/z\n---\n",
+		"cve/2020/14xxx/CVE-2020-14883.md": "---\nid: CVE-2020-14883\n---\n",
+	}
+	tr.write(files)
+	for p := range files {
+		tr.add(p)
+	}
+
+	repo := tr.repo()
+	dups, err := repo.DuplicateCVEs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []DuplicateCVE{{
+		CVEID: "CVE-2020-14882",
+		Path:  "cve/2020/14xxx/CVE-2020-14882.md",
+		Paths: []string{"cve/2020/0xxx/CVE-2020-14882.md", "cve/2020/14xxx/CVE-2020-14882.md"},
+	}}, dups)
+
+	err = repo.DedupeCVE(dups[0])
+	assert.NoError(t, err)
+
+	got, err := ioutil.ReadFile(path.Join(dir, "cve/2020/14xxx/CVE-2020-14882.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "---\nid: CVE-2020-14882\npocs:\n  - https://github.com/x/y\n  - https://github.com/z/z\n---\n", string(got))
+
+	exists, err := Exists(path.Join(dir, "cve/2020/0xxx/CVE-2020-14882.md"))
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	dups, err = repo.DuplicateCVEs(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, dups)
+}
+
+func TestRepo_DedupeCVE_ExistingTarget(t *testing.T) {
+	tr := newTestGitRepo(t)
+	defer os.RemoveAll(tr.dir)
+	dir := tr.dir
+
+	// The file at the correct path fails to parse, so isn't one of the duplicates
+	files := map[string]string{
+		"cve/2020/14xxx/CVE-2020-14882.md": "---\nid: [\n---\n",
+		"cve/2020/0xxx/CVE-2020-14882.md":  "---\nid: CVE-2020-14882\n---\n",
+		"cve/2020/1xxx/CVE-2020-14882.md":  "---\nid: CVE-2020-14882\n---\n",
+	}
+	tr.write(files)
+
+	repo := tr.repo()
+	dups, err := repo.DuplicateCVEs(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, dups, 1) {
+		assert.NotContains(t, dups[0].Paths, dups[0].Path)
+		assert.Error(t, repo.DedupeCVE(dups[0]))
+	}
+
+	got, err := ioutil.ReadFile(path.Join(dir, "cve/2020/14xxx/CVE-2020-14882.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, files["cve/2020/14xxx/CVE-2020-14882.md"], string(got))
+}
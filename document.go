@@ -0,0 +1,87 @@
+package cvebaser
+
+import (
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a markdown file in the repo with YAML front matter,
+// i.e. a CVE or Researcher
+type Document interface {
+	// Kind is the repo directory holding documents of this kind e.g. "cve"
+	Kind() string
+	// ID identifies the document within its kind e.g. CVE ID or researcher alias
+	ID() string
+	SetID(id string)
+	// SubPath is the canonical path of the document relative to its kind directory
+	SubPath() (string, error)
+	// FrontMatter is the YAML front matter node read by ParseMDFile, if any
+	FrontMatter() *yaml.Node
+	SetFrontMatter(node *yaml.Node)
+	// Body is the markdown content following the front matter
+	Body() string
+	SetBody(body string)
+}
+
+// documentKind registers a kind of Document
+type documentKind struct {
+	kind string
+	// pattern matches repo relative paths of documents of this kind
+	pattern string
+	new     func() Document
+}
+
+var documentKinds = []documentKind{
+	{"cve", "cve/*/*/*.md", func() Document { return &CVE{} }},
+	{"researcher", "researcher/*.md", func() Document { return &Researcher{} }},
+}
+
+// DocumentKinds returns the registered document kinds, which are also the
+// repo directories holding documents of each kind
+func DocumentKinds() []string {
+	kinds := make([]string, len(documentKinds))
+	for i, k := range documentKinds {
+		kinds[i] = k.kind
+	}
+	return kinds
+}
+
+// NewDocument returns an empty Document of the given kind
+func NewDocument(kind string) (Document, error) {
+	for _, k := range documentKinds {
+		if k.kind == kind {
+			return k.new(), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown document kind: %s", kind)
+}
+
+// DocumentPath returns the canonical repo relative path of a document
+// e.g. cve/2020/14xxx/CVE-2020-14882.md
+func DocumentPath(doc Document) (string, error) {
+	subPath, err := doc.SubPath()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(doc.Kind(), subPath), nil
+}
+
+func (c *CVE) Kind() string                   { return "cve" }
+func (c *CVE) ID() string                     { return c.CVEID }
+func (c *CVE) SetID(id string)                { c.CVEID = id }
+func (c *CVE) SubPath() (string, error)       { return CVESubPath(c.CVEID) }
+func (c *CVE) FrontMatter() *yaml.Node        { return c.node }
+func (c *CVE) SetFrontMatter(node *yaml.Node) { c.node = node }
+func (c *CVE) Body() string                   { return c.Advisory }
+func (c *CVE) SetBody(body string)            { c.Advisory = body }
+
+func (r *Researcher) Kind() string                   { return "researcher" }
+func (r *Researcher) ID() string                     { return r.Alias }
+func (r *Researcher) SetID(id string)                { r.Alias = id }
+func (r *Researcher) SubPath() (string, error)       { return ResearcherSubPath(r.Alias), nil }
+func (r *Researcher) FrontMatter() *yaml.Node        { return r.node }
+func (r *Researcher) SetFrontMatter(node *yaml.Node) { r.node = node }
+func (r *Researcher) Body() string                   { return r.Bio }
+func (r *Researcher) SetBody(body string)            { r.Bio = body }
@@ -0,0 +1,47 @@
+package cvebaser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	tests := []struct {
+		kind     string
+		in       string
+		wantID   string
+		wantPath string
+		wantBody string
+	}{
+		{"cve", "---\nid: CVE-2020-14882\n---\nadvisory\n", "CVE-2020-14882", "cve/2020/14xxx/CVE-2020-14882.md", "advisory\n"},
+		{"researcher", "---\nname: Orange Tsai\nalias: orange\ncves: []\n---\nbio\n", "orange", "researcher/orange.md", "bio\n"},
+	}
+	for _, tt := range tests {
+		doc, err := NewDocument(tt.kind)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.kind, doc.Kind())
+
+		err = ParseMDFile(bytes.NewReader([]byte(tt.in)), doc)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.wantID, doc.ID())
+		assert.Equal(t, tt.wantBody, doc.Body())
+		assert.NotNil(t, doc.FrontMatter())
+
+		p, err := DocumentPath(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.wantPath, p)
+		kind, err := PathIsType(p)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.kind, kind)
+
+		out, err := Compile(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.in, string(out))
+	}
+
+	assert.Equal(t, []string{"cve", "researcher"}, DocumentKinds())
+	_, err := NewDocument("unknown")
+	assert.Error(t, err)
+}
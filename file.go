@@ -4,135 +4,135 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 
-	"github.com/gohugoio/hugo/parser/pageparser"
 	"gopkg.in/yaml.v3"
 )
 
 // ParseCVEMDFile reads markdown file contents containing YAML and markdown
 // and returns CVE data struct
 func ParseCVEMDFile(reader io.Reader) (cve CVE, err error) {
-	pf, err := pageparser.ParseFrontMatterAndContent(reader)
-	if err != nil {
-		return cve, err
-	}
-	fm, err := yaml.Marshal(pf.FrontMatter)
-	if err != nil {
-		return cve, err
-	}
-	err = yaml.Unmarshal(fm, &cve)
-	if err != nil {
-		return cve, err
-	}
-	cve.Advisory = string(pf.Content)
+	err = ParseMDFile(reader, &cve)
 	return
 }
 
 // ParseResearcherMDFile reads markdown file contents containing YAML and markdown
 // and returns Researcher data struct
 func ParseResearcherMDFile(reader io.Reader) (researcher Researcher, err error) {
-	pf, err := pageparser.ParseFrontMatterAndContent(reader)
-	if err != nil {
-		return researcher, err
-	}
-	fm, err := yaml.Marshal(pf.FrontMatter)
-	if err != nil {
-		return researcher, err
-	}
-	err = yaml.Unmarshal(fm, &researcher)
-	if err != nil {
-		return researcher, err
-	}
-	researcher.Bio = string(pf.Content)
+	err = ParseMDFile(reader, &researcher)
 	return
 }
 
-// ParseMDFile reads markdown file contents containing YAML and markdown
-// and returns either CVE or Researcher data struct
-func ParseMDFile(r io.Reader, tPtr interface{}) error {
-	pf, err := pageparser.ParseFrontMatterAndContent(r)
+// ParseMDFile reads markdown file contents containing YAML and markdown into doc.
+// The YAML front matter is kept so that Compile preserves its comments, key order and styles.
+func ParseMDFile(r io.Reader, doc Document) error {
+	fm, err := parseFrontMatter(r)
 	if err != nil {
 		return fmt.Errorf("error parsing front matter: %v", err)
 	}
-	fm, err := yaml.Marshal(pf.FrontMatter)
-	if err != nil {
-		return fmt.Errorf("error marshaling yaml: %v", err)
-	}
-	err = yaml.Unmarshal(fm, tPtr)
+	node, err := fm.decode(doc)
 	if err != nil {
 		return fmt.Errorf("erorr unmarshaling yaml: %v", err)
 	}
-
-	// Set field value for markdown text depending on CVE or Researcher
-	switch t := tPtr.(type) {
-	case *CVE:
-		t.Advisory = string(pf.Content)
-	case *Researcher:
-		t.Bio = string(pf.Content)
-	default:
-		return fmt.Errorf("unknown type: %+v", tPtr)
-	}
-
+	doc.SetBody(string(fm.content))
+	doc.SetFrontMatter(node)
 	return nil
 }
 
 const yamlDelimLf = "---\n"
 
-func CompileToFile(
-	f *os.File,
-	path string,
-	t interface{}, /* CVE or Researcher to marshal */
-) error {
+// Compile renders a document to markdown file contents
+// with YAML front matter. Front matter read by ParseMDFile is updated in place,
+// keeping its comments, key order and scalar styles where values are unchanged.
+func Compile(doc Document) ([]byte, error) {
+	var out yaml.Node
+	err := out.Encode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling yaml: %v", err)
+	}
+	var v interface{} = &out
+	if orig := doc.FrontMatter(); orig != nil {
+		v = mergeNode(orig, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&out}})
+	}
+
 	// Configure yaml encoding for custom indent spacing
 	var d bytes.Buffer
 	yamlEncoder := yaml.NewEncoder(&d)
 	// go-yaml v3 now defaults to 4 spaces, so manually set to 2
 	yamlEncoder.SetIndent(2)
-	err := yamlEncoder.Encode(t)
+	err = yamlEncoder.Encode(v)
 	if err != nil {
-		return fmt.Errorf("error marshaling yaml to %s", path)
+		return nil, fmt.Errorf("error marshaling yaml: %v", err)
 	}
 	yamlEncoder.Close()
 
-	// Lead with marshaling first so that
-	// if fails doesn't error with a pre-maturely truncated file
-	// d, err := yaml.Marshal(t)
+	var b bytes.Buffer
+	b.WriteString(yamlDelimLf)
+	b.Write(d.Bytes())
+	b.WriteString(yamlDelimLf)
+	b.WriteString(doc.Body())
 
-	// TODO Check if file contents have changed before writing, otherwise return early
-
-	// Clear file for writing
-	f.Truncate(0)
-	f.Seek(0, 0)
+	return b.Bytes(), nil
+}
 
-	_, err = f.WriteString(yamlDelimLf)
-	if err != nil {
-		return fmt.Errorf("error writing to %s: %v", path, err)
-	}
-	_, err = f.Write(d.Bytes())
-	if err != nil {
-		return fmt.Errorf("error writing to %s: %v", path, err)
-	}
-	_, err = f.WriteString(yamlDelimLf)
+// CompileToFile compiles a document and writes it to path,
+// reporting whether the file changed. Unchanged files are left untouched.
+// Changes are written to a temp file in the same dir which is renamed over path,
+// so a failed write never leaves a partially written file.
+func CompileToFile(path string, doc Document) (changed bool, err error) {
+	b, err := Compile(doc)
 	if err != nil {
-		return fmt.Errorf("error writing to %s: %v", path, err)
+		return false, fmt.Errorf("error compiling %s: %v", path, err)
 	}
 
-	// Write markdown content from struct field depending on type CVE or Researcher
-	switch t := t.(type) {
-	case CVE:
-		_, err = f.WriteString(t.Advisory)
-		if err != nil {
-			return fmt.Errorf("error writing to %s: %v", path, err)
+	mode := os.FileMode(0644)
+	current, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		if bytes.Equal(current, b) {
+			return false, nil
 		}
-	case Researcher:
-		_, err = f.WriteString(t.Bio)
+		fi, err := os.Stat(path)
 		if err != nil {
-			return fmt.Errorf("error writing to %s: %v", path, err)
+			return false, fmt.Errorf("error reading %s: %v", path, err)
 		}
-	default:
-		return fmt.Errorf("unknown type: %+v", t)
+		mode = fi.Mode().Perm()
+	case !os.IsNotExist(err):
+		return false, fmt.Errorf("error reading %s: %v", path, err)
 	}
 
-	return nil
+	err = writeFileAtomic(path, b, mode)
+	if err != nil {
+		return false, fmt.Errorf("error writing to %s: %v", path, err)
+	}
+	return true, nil
+}
+
+// writeFileAtomic writes b to a temp file next to path and renames it over path
+func writeFileAtomic(path string, b []byte, mode os.FileMode) error {
+	// The name must not end in .md, or concurrent tree walks would pick up the temp file
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	// Remove the temp file unless it was renamed
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tmp.Name(), mode)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
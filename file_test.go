@@ -0,0 +1,116 @@
+package cvebaser
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	in := "---\nid: CVE-2020-14882\npocs:\n  - https://github.com/x/y\n---\nadvisory\n"
+
+	var cve CVE
+	err := ParseMDFile(bytes.NewReader([]byte(in)), &cve)
+	assert.NoError(t, err)
+
+	got, err := Compile(&cve)
+	assert.NoError(t, err)
+	assert.Equal(t, in, string(got))
+}
+
+func TestCompile_ExtraKeys(t *testing.T) {
+	in := "---\nid: CVE-2020-14882\ncvss: 9.8\ntags:\n  - rce\n---\n"
+
+	var cve CVE
+	err := ParseMDFile(bytes.NewReader([]byte(in)), &cve)
+	assert.NoError(t, err)
+	assert.Equal(t, 9.8, cve.Extra["cvss"])
+
+	got, err := Compile(&cve)
+	assert.NoError(t, err)
+	assert.Equal(t, in, string(got))
+}
+
+func TestCompile_PreservesFrontMatter(t *testing.T) {
+	in := "---\n# weblogic\nid: CVE-2020-14882 # console RCE\ncvss: 9.80\ntitle: 'Oracle WebLogic'\n" +
+		"pocs:\n  - https://github.com/z/z # original\n  - https://github.com/a/a\n  - https://github.com/a/a\n" +
+		"writeups: [\"https://example.com/writeup\"]\ncourses:\n  - https://example.com/course\n---\nadvisory\n"
+
+	var cve CVE
+	err := ParseMDFile(bytes.NewReader([]byte(in)), &cve)
+	assert.NoError(t, err)
+
+	// Unchanged documents round trip byte for byte
+	got, err := Compile(&cve)
+	assert.NoError(t, err)
+	assert.Equal(t, in, string(got))
+
+	// Only edited fields change
+	cve.Pocs = append(SortUniqStrings(cve.Pocs), "https://github.com/b/b")
+	cve.Courses = nil
+	got, err = Compile(&cve)
+	assert.NoError(t, err)
+	want := "---\n# weblogic\nid: CVE-2020-14882 # console RCE\ncvss: 9.80\ntitle: 'Oracle WebLogic'\n" +
+		"pocs:\n  - https://github.com/a/a\n  - https://github.com/z/z # original\n  - https://github.com/b/b\n" +
+		"writeups: [\"https://example.com/writeup\"]\n---\nadvisory\n"
+	assert.Equal(t, want, string(got))
+}
+
+func TestCompileToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvebaser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := path.Join(dir, "CVE-2020-14882.md")
+	err = ioutil.WriteFile(p, []byte("---\nid: CVE-2020-14882\npocs:\n- https://github.com/jas502n/CVE-2020-14882\n---\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cve := CVE{CVEID: "CVE-2020-14882", Pocs: []string{"https://github.com/jas502n/CVE-2020-14882"}}
+
+	changed, err := CompileToFile(p, &cve)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	want, err := Compile(&cve)
+	assert.NoError(t, err)
+	got, err := ioutil.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+
+	fi, err := os.Stat(p)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	changed, err = CompileToFile(p, &cve)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// Temp files are renamed into place
+	entries, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestParseMDFile_NotMapping(t *testing.T) {
+	for _, in := range []string{
+		"---\n- a\n- b\n---\nadvisory\n",
+		"---\nhello\n---\nadvisory\n",
+	} {
+		var cve CVE
+		err := ParseMDFile(bytes.NewReader([]byte(in)), &cve)
+		assert.Error(t, err, in)
+	}
+
+	// Empty front matter is an empty document
+	var cve CVE
+	err := ParseMDFile(bytes.NewReader([]byte("---\n---\nadvisory\n")), &cve)
+	assert.NoError(t, err)
+	assert.Equal(t, "advisory\n", cve.Advisory)
+}
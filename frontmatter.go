@@ -0,0 +1,178 @@
+package cvebaser
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/gohugoio/hugo/parser/metadecoders"
+	"github.com/gohugoio/hugo/parser/pageparser"
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatter is the raw front matter and content of a markdown file
+type frontMatter struct {
+	source  []byte
+	format  metadecoders.Format
+	content []byte
+}
+
+// parseFrontMatter splits markdown file contents into front matter and content,
+// as pageparser.ParseFrontMatterAndContent but keeping the front matter source
+func parseFrontMatter(r io.Reader) (fm frontMatter, err error) {
+	psr, err := pageparser.Parse(r, pageparser.Config{})
+	if err != nil {
+		return fm, err
+	}
+
+	psr.Iterator().PeekWalk(func(item pageparser.Item) bool {
+		if fm.source != nil {
+			// The rest is content
+			fm.content = psr.Input()[item.Pos:]
+			return false
+		} else if item.IsFrontMatter() {
+			fm.format = pageparser.FormatFromFrontMatterType(item.Type)
+			fm.source = item.Val
+		}
+		return true
+	})
+	return fm, nil
+}
+
+// decode decodes the front matter into tPtr. YAML front matter is decoded via
+// a yaml.Node, which is returned so that it can be reused when compiling.
+func (fm frontMatter) decode(tPtr interface{}) (*yaml.Node, error) {
+	if fm.format != metadecoders.YAML {
+		m, err := metadecoders.Default.UnmarshalToMap(fm.source, fm.format)
+		if err != nil {
+			return nil, err
+		}
+		b, err := yaml.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling yaml: %v", err)
+		}
+		return nil, yaml.Unmarshal(b, tPtr)
+	}
+
+	var doc yaml.Node
+	err := yaml.Unmarshal(fm.source, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		// Empty front matter has no node to preserve
+		return nil, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("front matter must be a mapping, got %s", doc.Content[0].ShortTag())
+	}
+	err = doc.Decode(tPtr)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// mergeNode returns orig updated with the values of fresh, keeping the comments,
+// key order and scalar styles of orig for values that are unchanged.
+// Neither node is modified.
+func mergeNode(orig, fresh *yaml.Node) *yaml.Node {
+	if orig.Kind != fresh.Kind {
+		return withComments(fresh, orig)
+	}
+
+	switch orig.Kind {
+	case yaml.DocumentNode:
+		n := *orig
+		n.Content = []*yaml.Node{mergeNode(orig.Content[0], fresh.Content[0])}
+		return &n
+	case yaml.MappingNode:
+		return mergeMapping(orig, fresh)
+	case yaml.SequenceNode:
+		return mergeSequence(orig, fresh)
+	case yaml.ScalarNode:
+		if sameScalar(orig, fresh) {
+			return orig
+		}
+		n := withComments(fresh, orig)
+		// Keep quoting style of strings e.g. "x" stays double quoted
+		if fresh.ShortTag() == "!!str" && orig.ShortTag() == "!!str" {
+			n.Style = orig.Style
+		}
+		return n
+	}
+	return withComments(fresh, orig)
+}
+
+// mergeMapping keeps keys of orig that are still in fresh in their original
+// order, followed by keys new in fresh
+func mergeMapping(orig, fresh *yaml.Node) *yaml.Node {
+	freshValues := make(map[string]*yaml.Node, len(fresh.Content)/2)
+	for i := 0; i+1 < len(fresh.Content); i += 2 {
+		freshValues[fresh.Content[i].Value] = fresh.Content[i+1]
+	}
+
+	n := *orig
+	n.Content = nil
+	seen := make(map[string]bool, len(orig.Content)/2)
+	for i := 0; i+1 < len(orig.Content); i += 2 {
+		k, v := orig.Content[i], orig.Content[i+1]
+		fv, ok := freshValues[k.Value]
+		if !ok {
+			// Removed, e.g. an omitempty field that is now empty
+			continue
+		}
+		seen[k.Value] = true
+		n.Content = append(n.Content, k, mergeNode(v, fv))
+	}
+	for i := 0; i+1 < len(fresh.Content); i += 2 {
+		if !seen[fresh.Content[i].Value] {
+			n.Content = append(n.Content, fresh.Content[i], fresh.Content[i+1])
+		}
+	}
+	return &n
+}
+
+// mergeSequence follows the item order of fresh, reusing items of orig with the same value
+func mergeSequence(orig, fresh *yaml.Node) *yaml.Node {
+	used := make([]bool, len(orig.Content))
+	n := *orig
+	n.Content = nil
+	for _, fv := range fresh.Content {
+		var item *yaml.Node
+		for i, ov := range orig.Content {
+			if !used[i] && ov.Kind == yaml.ScalarNode && fv.Kind == yaml.ScalarNode && sameScalar(ov, fv) {
+				used[i] = true
+				item = ov
+				break
+			}
+		}
+		if item == nil {
+			item = fv
+		}
+		n.Content = append(n.Content, item)
+	}
+	return &n
+}
+
+// sameScalar reports whether two scalar nodes decode to the same value,
+// e.g. 'x' and x, or 1e3 and 1000
+func sameScalar(a, b *yaml.Node) bool {
+	if a.Value == b.Value && a.ShortTag() == b.ShortTag() {
+		return true
+	}
+	var av, bv interface{}
+	if a.Decode(&av) != nil || b.Decode(&bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// withComments returns a copy of n with the comments of orig
+func withComments(n, orig *yaml.Node) *yaml.Node {
+	c := *n
+	c.HeadComment = orig.HeadComment
+	c.LineComment = orig.LineComment
+	c.FootComment = orig.FootComment
+	return &c
+}
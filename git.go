@@ -3,8 +3,16 @@ package cvebaser
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -60,6 +68,111 @@ func (r *Repo) initGitRepo(clone, pull bool) error {
 	return err
 }
 
+// MoveFile renames a file from one repo relative path to another, creating
+// directories as needed, and stages the rename in the git worktree along with
+// any unstaged changes to the file. Untracked files are staged at the new path.
+// Refuses to overwrite an existing file at the target path, or to move to a path
+// that isn't a cve or researcher file inside the repo.
+func (r *Repo) MoveFile(from, to string) error {
+	// Targets are built from file content e.g. a researcher alias, so check them
+	// before creating any dirs
+	err := r.checkMoveTarget(to)
+	if err != nil {
+		return err
+	}
+
+	exists, err := Exists(r.GetFullPath(to))
+	if err != nil {
+		return fmt.Errorf("error checking file exists: %v", err)
+	}
+	if exists {
+		return fmt.Errorf("target already exists: %s", to)
+	}
+
+	w, err := r.worktree()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(path.Dir(r.GetFullPath(to)), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating dir for %s: %v", to, err)
+	}
+	_, err = w.Move(from, to)
+	if err == index.ErrEntryNotFound {
+		// Untracked files aren't in the index to move
+		err = os.Rename(r.GetFullPath(from), r.GetFullPath(to))
+	}
+	if err != nil {
+		return fmt.Errorf("error moving %s to %s: %v", from, to, err)
+	}
+	// Move stages the blob already in the index, not the file as rewritten by lint
+	_, err = w.Add(to)
+	if err != nil {
+		return fmt.Errorf("error staging %s: %v", to, err)
+	}
+	return nil
+}
+
+// checkMoveTarget checks repo relative path to is a cve or researcher file inside the repo,
+// that isn't hidden from tree walks
+func (r *Repo) checkMoveTarget(to string) error {
+	if path.Clean(to) != to || strings.HasPrefix(path.Base(to), ".") {
+		return fmt.Errorf("invalid target: %s", to)
+	}
+	if _, err := PathIsType(to); err != nil {
+		return fmt.Errorf("invalid target: %v", err)
+	}
+	rel, err := filepath.Rel(r.DirPath, r.GetFullPath(to))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("target outside repo: %s", to)
+	}
+	return nil
+}
+
+// RemoveFile deletes a repo relative file and stages the removal in the git worktree.
+// Untracked files are deleted from disk.
+func (r *Repo) RemoveFile(p string) error {
+	w, err := r.worktree()
+	if err != nil {
+		return err
+	}
+	_, err = w.Remove(p)
+	if err == index.ErrEntryNotFound {
+		err = os.Remove(r.GetFullPath(p))
+	}
+	if err != nil {
+		return fmt.Errorf("error removing %s: %v", p, err)
+	}
+	return nil
+}
+
+// StageFile adds a repo relative file to the git index
+func (r *Repo) StageFile(p string) error {
+	w, err := r.worktree()
+	if err != nil {
+		return err
+	}
+	_, err = w.Add(p)
+	if err != nil {
+		return fmt.Errorf("error staging %s: %v", p, err)
+	}
+	return nil
+}
+
+// worktree opens the git worktree of the repo
+func (r *Repo) worktree() (*git.Worktree, error) {
+	gitRepo, err := git.PlainOpen(r.DirPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading git repo: %v", err)
+	}
+	w, err := gitRepo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("error loading git worktree: %v", err)
+	}
+	return w, nil
+}
+
 func (r *Repo) CheckFilenamesFromCommit(h string) ([]string, error) {
 	gitRepo, err := git.PlainOpen(r.DirPath)
 	if err != nil {
@@ -103,6 +216,282 @@ func (r *Repo) CheckFilenamesFromCommit(h string) ([]string, error) {
 	return files, nil
 }
 
+// FilenamesFromRange returns files added, modified or renamed by any commit
+// reachable from head but not from base, as in `git log base..head`.
+// Files deleted by head are excluded.
+func (r *Repo) FilenamesFromRange(base, head string) ([]string, error) {
+	gitRepo, err := git.PlainOpen(r.DirPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading git repo: %v", err)
+	}
+	baseCommit, err := resolveCommit(gitRepo, base)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := resolveCommit(gitRepo, head)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect commits reachable from base to exclude from the walk
+	exclude := make(map[plumbing.Hash]bool)
+	err = object.NewCommitPreorderIter(baseCommit, nil, nil).ForEach(func(c *object.Commit) error {
+		exclude[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking history of %s: %v", base, err)
+	}
+
+	var files []string
+	err = object.NewCommitPreorderIter(headCommit, exclude, nil).ForEach(func(c *object.Commit) error {
+		// Merge commits only repeat changes of the commits being merged
+		if c.NumParents() != 1 {
+			return nil
+		}
+		modified, err := getFilesModified(c)
+		if err != nil {
+			return err
+		}
+		files = append(files, modified...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking commits %s..%s: %v", base, head, err)
+	}
+
+	// Lint only the final version, so drop files deleted later in the range
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("error getting tree from commit: %v", err)
+	}
+	var existing []string
+	for _, f := range SortUniqStrings(files) {
+		if _, err := headTree.File(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+
+	return existing, nil
+}
+
+// MergeBase returns the hash of the best common ancestor of two revisions,
+// as in `git merge-base a b`
+func (r *Repo) MergeBase(a, b string) (string, error) {
+	gitRepo, err := git.PlainOpen(r.DirPath)
+	if err != nil {
+		return "", fmt.Errorf("error loading git repo: %v", err)
+	}
+	aCommit, err := resolveCommit(gitRepo, a)
+	if err != nil {
+		return "", err
+	}
+	bCommit, err := resolveCommit(gitRepo, b)
+	if err != nil {
+		return "", err
+	}
+	bases, err := aCommit.MergeBase(bCommit)
+	if err != nil {
+		return "", fmt.Errorf("error finding merge base of %s and %s: %v", a, b, err)
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("no merge base for %s and %s", a, b)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+// StagedFilenames returns cve and researcher files added or modified
+// in the git index, as committed by the next `git commit`
+func (r *Repo) StagedFilenames() ([]string, error) {
+	return r.statusFilenames(func(fs *git.FileStatus) bool {
+		return isChanged(fs.Staging)
+	})
+}
+
+// WorktreeFilenames returns cve and researcher files with uncommitted changes,
+// whether staged or not, including untracked files
+func (r *Repo) WorktreeFilenames() ([]string, error) {
+	return r.statusFilenames(func(fs *git.FileStatus) bool {
+		return isChanged(fs.Staging) || isChanged(fs.Worktree) || fs.Worktree == git.Untracked
+	})
+}
+
+// statusFilenames returns cve and researcher files from the worktree status matching fn.
+// Deleted files are excluded.
+func (r *Repo) statusFilenames(fn func(fs *git.FileStatus) bool) ([]string, error) {
+	gitRepo, err := git.PlainOpen(r.DirPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading git repo: %v", err)
+	}
+	w, err := gitRepo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("error loading git worktree: %v", err)
+	}
+	status, err := w.Status()
+	if err != nil {
+		return nil, fmt.Errorf("error getting git status: %v", err)
+	}
+
+	var files []string
+	for p, fs := range status {
+		if fs.Staging == git.Deleted || fs.Worktree == git.Deleted || !fn(fs) {
+			continue
+		}
+		if path.Ext(p) != ".md" || !(strings.HasPrefix(p, "cve/") || strings.HasPrefix(p, "researcher/")) {
+			continue
+		}
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// FileReader reads the content of a repo relative file
+type FileReader func(p string) ([]byte, error)
+
+// IndexReader returns a FileReader for the content of files as staged in the
+// git index, which may differ from the working tree
+func (r *Repo) IndexReader() (FileReader, error) {
+	gitRepo, err := git.PlainOpen(r.DirPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading git repo: %v", err)
+	}
+	idx, err := gitRepo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("error reading git index: %v", err)
+	}
+	return func(p string) ([]byte, error) {
+		e, err := idx.Entry(p)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from git index: %v", p, err)
+		}
+		b, err := readBlob(gitRepo, e.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from git index: %v", p, err)
+		}
+		return b, nil
+	}, nil
+}
+
+// RevisionReader returns a FileReader for the content of files at a git revision
+// e.g. a hash, branch, tag or HEAD~2
+func (r *Repo) RevisionReader(rev string) (FileReader, error) {
+	gitRepo, err := git.PlainOpen(r.DirPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading git repo: %v", err)
+	}
+	c, err := resolveCommit(gitRepo, rev)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("error getting tree from commit: %v", err)
+	}
+	return func(p string) ([]byte, error) {
+		f, err := tree.File(p)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s at %s: %v", p, rev, err)
+		}
+		b, err := readBlob(gitRepo, f.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s at %s: %v", p, rev, err)
+		}
+		return b, nil
+	}, nil
+}
+
+// readBlob returns the content of the blob with the given hash
+func readBlob(gitRepo *git.Repository, h plumbing.Hash) ([]byte, error) {
+	blob, err := gitRepo.BlobObject(h)
+	if err != nil {
+		return nil, err
+	}
+	rd, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return ioutil.ReadAll(rd)
+}
+
+// isChanged reports whether a status code is a change to lint
+func isChanged(c git.StatusCode) bool {
+	return c == git.Added || c == git.Modified || c == git.Renamed || c == git.Copied
+}
+
+// GitDir returns the full path of the repo's git dir. In linked worktrees and
+// submodules .git is a file naming the git dir with a gitdir: line, which is followed.
+func (r *Repo) GitDir() (string, error) {
+	p := r.GetFullPath(".git")
+	fi, err := os.Stat(p)
+	if err != nil {
+		return "", fmt.Errorf("error reading git dir: %v", err)
+	}
+	if fi.IsDir() {
+		return p, nil
+	}
+
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("error reading git dir: %v", err)
+	}
+	line := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("invalid .git file: %s", p)
+	}
+	dir := filepath.FromSlash(strings.TrimSpace(strings.TrimPrefix(line, "gitdir:")))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.DirPath, dir)
+	}
+	return dir, nil
+}
+
+// CommonDir returns the full path of the git dir shared by all worktrees.
+// A linked worktree's git dir has a commondir file pointing to it,
+// otherwise it's the git dir itself.
+func (r *Repo) CommonDir() (string, error) {
+	gitDir, err := r.GitDir()
+	if err != nil {
+		return "", err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir"))
+	if os.IsNotExist(err) {
+		return gitDir, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading git common dir: %v", err)
+	}
+	dir := filepath.FromSlash(strings.TrimSpace(string(b)))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// HookPath returns the full path of the named git hook e.g. pre-commit.
+// Hooks live in the common git dir, so are shared by linked worktrees.
+func (r *Repo) HookPath(name string) (string, error) {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "hooks", name), nil
+}
+
+// resolveCommit returns the commit for a revision e.g. a hash, branch, tag or HEAD~2
+func resolveCommit(gitRepo *git.Repository, rev string) (*object.Commit, error) {
+	h, err := gitRepo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving revision %s: %v", rev, err)
+	}
+	c, err := gitRepo.CommitObject(*h)
+	if err != nil {
+		return nil, fmt.Errorf("error getting commit %s: %v", rev, err)
+	}
+	return c, nil
+}
+
 // getFilesModified returns a slice of files modified in the given commit.
 // Git DiffTree compares the content and mode of the blobs found via two tree objects.
 // https://github.com/go-git/go-git/blob/218a744b6995a89f5c322aa58e79138d65392ea6/plumbing/object/difftree.go
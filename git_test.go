@@ -1,8 +1,14 @@
 package cvebaser
 
 import (
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,3 +33,272 @@ func TestRepo_CheckFilenamesFromCommit(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, got, want)
 }
+
+// testGitRepo is a git repo in a temp dir
+type testGitRepo struct {
+	t   *testing.T
+	dir string
+	w   *git.Worktree
+}
+
+// newTestGitRepo inits a git repo in a temp dir. Callers remove dir.
+func newTestGitRepo(t *testing.T) *testGitRepo {
+	dir, err := ioutil.TempDir("", "cvebaser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitRepo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testGitRepo{t: t, dir: dir, w: w}
+}
+
+// write writes files to the working tree, keyed by repo relative path
+func (r *testGitRepo) write(files map[string]string) {
+	for p, content := range files {
+		fp := path.Join(r.dir, p)
+		if err := os.MkdirAll(path.Dir(fp), 0755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fp, []byte(content), 0644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+}
+
+// add stages the given paths
+func (r *testGitRepo) add(paths ...string) {
+	for _, p := range paths {
+		if _, err := r.w.Add(p); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+}
+
+// commit writes and stages files, removes deleted files and commits all
+// staged changes, returning the commit hash
+func (r *testGitRepo) commit(files map[string]string, deleted ...string) string {
+	r.write(files)
+	for p := range files {
+		r.add(p)
+	}
+	for _, p := range deleted {
+		if _, err := r.w.Remove(p); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	h, err := r.w.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return h.String()
+}
+
+// repo opens the git repo as a Repo
+func (r *testGitRepo) repo() *Repo {
+	repo, err := NewRepo(r.dir, &GitOpts{})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return repo
+}
+
+func TestRepo_MoveFile(t *testing.T) {
+	tr := newTestGitRepo(t)
+	defer os.RemoveAll(tr.dir)
+
+	from := "cve/2016/0xxx/CVE-2020-14883.md"
+	to := "cve/2020/14xxx/CVE-2020-14883.md"
+	tr.write(map[string]string{from: "---\nid: CVE-2020-14883\n---\n"})
+	tr.add(from)
+
+	// Rewritten since staged, as by lint before a move
+	tr.write(map[string]string{from: "---\nid: CVE-2020-14883\npocs: []\n---\n"})
+
+	repo := tr.repo()
+	err := repo.MoveFile(from, to)
+	assert.NoError(t, err)
+
+	status, err := tr.w.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, git.Added, status.File(to).Staging)
+	assert.Equal(t, git.Unmodified, status.File(to).Worktree)
+
+	// Untracked files are moved and staged
+	untracked := "cve/2016/0xxx/CVE-2020-14884.md"
+	untrackedTo := "cve/2020/14xxx/CVE-2020-14884.md"
+	tr.write(map[string]string{untracked: "---\nid: CVE-2020-14884\n---\n"})
+	err = repo.MoveFile(untracked, untrackedTo)
+	assert.NoError(t, err)
+	status, err = tr.w.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, git.Added, status.File(untrackedTo).Staging)
+	exists, err := Exists(path.Join(tr.dir, untracked))
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	// Refuse to overwrite existing target
+	tr.write(map[string]string{from: "---\nid: CVE-2020-14883\n---\n"})
+	err = repo.MoveFile(from, to)
+	assert.Error(t, err)
+
+	// Refuse targets that aren't cve or researcher files inside the repo
+	for _, bad := range []string{
+		"researcher/.md",
+		"researcher/../../outside/researcher/x.md",
+		"../outside/cve/2020/14xxx/CVE-2020-14883.md",
+		"notes/CVE-2020-14883.md",
+	} {
+		assert.Error(t, repo.MoveFile(from, bad), bad)
+	}
+	exists, err = Exists(path.Join(tr.dir, "../outside"))
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestRepo_FilenamesFromRange(t *testing.T) {
+	tr := newTestGitRepo(t)
+	defer os.RemoveAll(tr.dir)
+	commit := tr.commit
+
+	base := commit(map[string]string{
+		"README.md":                        "readme",
+		"cve/2020/14xxx/CVE-2020-14882.md": "a",
+		"researcher/orange.md":             "a",
+	})
+	commit(map[string]string{
+		"cve/2020/14xxx/CVE-2020-14882.md": "b",
+		"cve/2020/14xxx/CVE-2020-14883.md": "a",
+	})
+	_, err := tr.w.Move("researcher/orange.md", "researcher/orange-tsai.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit(map[string]string{
+		"cve/2021/3xxx/CVE-2021-3156.md": "a",
+	})
+	head := commit(nil, "cve/2020/14xxx/CVE-2020-14883.md")
+
+	repo := tr.repo()
+
+	got, err := repo.FilenamesFromRange(base, head)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"cve/2020/14xxx/CVE-2020-14882.md",
+		"cve/2021/3xxx/CVE-2021-3156.md",
+		"researcher/orange-tsai.md",
+	}, got)
+
+	mb, err := repo.MergeBase(base, "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, base, mb)
+
+	_, err = repo.FilenamesFromRange("nope", head)
+	assert.Error(t, err)
+}
+
+func TestRepo_StagedFilenames(t *testing.T) {
+	tr := newTestGitRepo(t)
+	defer os.RemoveAll(tr.dir)
+
+	files := make(map[string]string)
+	for _, p := range []string{"README.md", "cve/2020/14xxx/CVE-2020-14882.md", "researcher/orange.md", "researcher/notes.txt"} {
+		files[p] = p
+	}
+	tr.write(files)
+	tr.add("README.md", "cve/2020/14xxx/CVE-2020-14882.md", "researcher/notes.txt")
+
+	repo := tr.repo()
+	got, err := repo.StagedFilenames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cve/2020/14xxx/CVE-2020-14882.md"}, got)
+
+	got, err = repo.WorktreeFilenames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cve/2020/14xxx/CVE-2020-14882.md", "researcher/orange.md"}, got)
+}
+
+func TestRepo_GitDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvebaser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo := &Repo{DirPath: path.Join(dir, "repo")}
+	if err = os.MkdirAll(path.Join(repo.DirPath, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GitDir()
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(repo.DirPath, ".git"), got)
+
+	// Linked worktrees and submodules have a .git file pointing to the git dir
+	worktree := &Repo{DirPath: path.Join(dir, "worktree")}
+	if err = os.MkdirAll(worktree.DirPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path.Join(worktree.DirPath, ".git"), []byte("gitdir: ../repo/.git/worktrees/worktree\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = worktree.GitDir()
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(repo.DirPath, ".git/worktrees/worktree"), got)
+}
+
+func TestRepo_HookPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvebaser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo := &Repo{DirPath: path.Join(dir, "repo")}
+	if err = os.MkdirAll(path.Join(repo.DirPath, ".git/worktrees/worktree"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.HookPath("pre-commit")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(repo.DirPath, ".git/hooks/pre-commit"), got)
+
+	// A linked worktree's hooks are in the common dir of the main repo
+	err = ioutil.WriteFile(path.Join(repo.DirPath, ".git/worktrees/worktree/commondir"), []byte("../..\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree := &Repo{DirPath: path.Join(dir, "worktree")}
+	if err = os.MkdirAll(worktree.DirPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path.Join(worktree.DirPath, ".git"), []byte("gitdir: ../repo/.git/worktrees/worktree\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = worktree.HookPath("pre-commit")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(repo.DirPath, ".git/hooks/pre-commit"), got)
+
+	// Submodules have no commondir, so hooks are in their own git dir
+	submodule := &Repo{DirPath: path.Join(dir, "submodule")}
+	if err = os.MkdirAll(submodule.DirPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path.Join(submodule.DirPath, ".git"), []byte("gitdir: ../repo/.git/modules/submodule\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = submodule.HookPath("pre-commit")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(repo.DirPath, ".git/modules/submodule/hooks/pre-commit"), got)
+
+	_, err = (&Repo{DirPath: path.Join(dir, "missing")}).HookPath("pre-commit")
+	assert.Error(t, err)
+}
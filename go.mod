@@ -8,6 +8,7 @@ require (
 	github.com/gobwas/cli v0.0.0-20201206183336-d4840bb5a2b7
 	github.com/gohugoio/hugo v0.79.0
 	github.com/karrick/godirwalk v1.16.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/afero v1.5.1 // indirect
 	github.com/stretchr/testify v1.6.1
 	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776
@@ -0,0 +1,191 @@
+package lint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cvebase/cvebaser"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCacheDir is the lint cache dir relative to the repo's git dir
+const DefaultCacheDir = "cvebaser"
+
+const cacheFile = "lint-cache.json"
+
+// Cache records lint results of files that need no changes, keyed by path
+// and content hash, so unchanged files can be skipped on the next run.
+// Methods on a nil Cache are no-ops.
+type Cache struct {
+	dir string
+	key string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is the lint result of a file with the given content hash
+type cacheEntry struct {
+	Hash     string     `json:"hash"`
+	Findings []Finding  `json:"findings,omitempty"`
+	Index    indexEntry `json:"index"`
+}
+
+// cacheVersion is bumped when the format of cache entries changes
+const cacheVersion = 2
+
+// cacheData is the on-disk cache format
+type cacheData struct {
+	Key     string                `json:"key"`
+	Entries map[string]cacheEntry `json:"entries"`
+}
+
+// CacheKey returns the ruleset version identifying lint results from the
+// cvebaser build, config and enabled rules with their severities
+func CacheKey(cfg *Config, reg *Registry) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "cvebaser %s\n", cvebaser.BuildVersion())
+	fmt.Fprintf(h, "cache %d\n", cacheVersion)
+
+	// Worker count doesn't change results
+	c := *cfg
+	c.Workers = 0
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("error encoding config: %v", err)
+	}
+	h.Write(b)
+
+	for _, rule := range reg.Rules() {
+		if reg.Enabled(rule.ID()) {
+			fmt.Fprintf(h, "%s %s\n", rule.ID(), reg.Severity(rule))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// RepoCacheDir returns the default lint cache dir of a repo, inside its git dir
+// so that linked worktrees and submodules each have their own cache
+func RepoCacheDir(repo *cvebaser.Repo) (string, error) {
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, DefaultCacheDir), nil
+}
+
+// OpenCache loads the cache in dir. Entries are discarded if they were
+// saved with a different key, or the cache file is missing or unreadable.
+func OpenCache(dir, key string) (*Cache, error) {
+	cache := &Cache{dir: dir, key: key, entries: make(map[string]cacheEntry)}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, cacheFile))
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading lint cache: %v", err)
+	}
+	var data cacheData
+	if err = json.Unmarshal(b, &data); err != nil || data.Key != key {
+		return cache, nil
+	}
+	if data.Entries != nil {
+		cache.entries = data.Entries
+	}
+	return cache, nil
+}
+
+// Save writes the cache to disk, replacing the cache file atomically
+func (cache *Cache) Save() error {
+	if cache == nil {
+		return nil
+	}
+	cache.mu.Lock()
+	b, err := json.Marshal(cacheData{Key: cache.key, Entries: cache.entries})
+	cache.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding lint cache: %v", err)
+	}
+
+	err = os.MkdirAll(cache.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating lint cache dir: %v", err)
+	}
+	tmp, err := ioutil.TempFile(cache.dir, cacheFile+".tmp")
+	if err != nil {
+		return fmt.Errorf("error writing lint cache: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(cache.dir, cacheFile))
+	}
+	if err != nil {
+		return fmt.Errorf("error writing lint cache: %v", err)
+	}
+	return nil
+}
+
+// Len returns the number of cached files
+func (cache *Cache) Len() int {
+	if cache == nil {
+		return 0
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return len(cache.entries)
+}
+
+// replay adds cached findings for file p to c, returning its index entry and
+// reporting whether the cache had an entry for the given content
+func (cache *Cache) replay(c *Collector, p string, content []byte) (indexEntry, bool) {
+	if cache == nil {
+		return indexEntry{}, false
+	}
+	cache.mu.Lock()
+	e, ok := cache.entries[p]
+	cache.mu.Unlock()
+	if !ok || e.Hash != contentHash(content) {
+		return indexEntry{}, false
+	}
+	for _, f := range e.Findings {
+		c.Add(f)
+	}
+	return e.Index, true
+}
+
+// store records the findings and index entry for file p with the given content
+func (cache *Cache) store(p string, content []byte, findings []Finding, idx indexEntry) {
+	if cache == nil {
+		return
+	}
+	e := cacheEntry{Hash: contentHash(content), Findings: findings, Index: idx}
+	cache.mu.Lock()
+	cache.entries[p] = e
+	cache.mu.Unlock()
+}
+
+// forget removes any entry for file p
+func (cache *Cache) forget(p string) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	delete(cache.entries, p)
+	cache.mu.Unlock()
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
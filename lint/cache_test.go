@@ -0,0 +1,71 @@
+package lint
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	lr := newTestLinter(t, map[string]string{
+		"cve/2020/14xxx/CVE-2020-14882.md": "---\nid: CVE-2020-14882\n---\n",
+		"cve/2016/0xxx/CVE-2020-14883.md":  "---\nid: CVE-2020-14883\npocs:\n  - https://github.com/x/y\n---\n",
+		// Repo rules report a missing cve file and a duplicate from the cached index
+		"researcher/orange.md":            "---\nname: Orange Tsai\nalias: orange\ncves:\n  - CVE-2019-11510\n  - CVE-2020-14882\n---\n",
+		"cve/2020/1xxx/CVE-2020-14882.md": "---\nid: CVE-2020-14882\n---\n",
+	})
+	defer os.RemoveAll(lr.DirPath)
+	lr.Check = true
+	lr.initRules()
+
+	cfg := &Config{}
+	key, err := CacheKey(cfg, lr.Rules)
+	assert.NoError(t, err)
+	dir := path.Join(lr.DirPath, DefaultCacheDir)
+
+	lint := func() []Finding {
+		lr.Stats = nil
+		lr.Cache, err = OpenCache(dir, key)
+		assert.NoError(t, err)
+		lr.Start()
+		c, err := lr.LintAll(context.Background(), 2)
+		lr.End(c)
+		assert.NoError(t, err)
+		assert.NoError(t, lr.Cache.Save())
+		return c.Findings()
+	}
+
+	want := lint()
+	assert.EqualValues(t, 0, lr.Stats.Cached)
+	// The misplaced cves aren't cached
+	assert.Equal(t, 2, lr.Cache.Len())
+	var repoFindings int
+	for _, f := range want {
+		if f.RuleID == RuleResearcherCVERef || f.RuleID == RuleCVEDuplicate {
+			repoFindings++
+		}
+	}
+	assert.Equal(t, 2, repoFindings)
+
+	got := lint()
+	assert.EqualValues(t, 2, lr.Stats.Cached)
+	assert.Equal(t, want, got)
+
+	// Changing config invalidates the cache
+	cfg.Ignore = []string{"cve/1999/**"}
+	key2, err := CacheKey(cfg, lr.Rules)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, key2)
+	cache, err := OpenCache(dir, key2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cache.Len())
+
+	// As does changing rules
+	assert.NoError(t, lr.Rules.Disable(RuleCVEShared))
+	key3, err := CacheKey(&Config{}, lr.Rules)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, key3)
+}
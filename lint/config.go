@@ -0,0 +1,169 @@
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/cvebase/cvebaser"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the lint config file name loaded from the repo root
+const DefaultConfigFile = ".cvebaser.yaml"
+
+// DefaultWorkers is the number of concurrent lint workers when not configured
+const DefaultWorkers = 20
+
+// Config is the repo level lint configuration, e.g.
+//
+//	rules:
+//	  disable: [cve-shared]
+//	  severity:
+//	    researcher-cves: error
+//	required:
+//	  references: true
+//	  advisory: false
+//	poc_hosts:
+//	  deny: [example.com]
+//	ignore:
+//	  - cve/1999/**
+//	workers: 20
+//	cache_dir: .git/cvebaser
+type Config struct {
+	Rules struct {
+		Enable   []string            `yaml:"enable"`
+		Disable  []string            `yaml:"disable"`
+		Severity map[string]Severity `yaml:"severity"`
+	} `yaml:"rules"`
+	Required struct {
+		References *bool `yaml:"references"`
+		Advisory   bool  `yaml:"advisory"`
+	} `yaml:"required"`
+	PocHosts struct {
+		Allow []string `yaml:"allow"`
+		Deny  []string `yaml:"deny"`
+	} `yaml:"poc_hosts"`
+	// Ignore is repo relative path globs to skip; ** matches any number of directories
+	Ignore  []string `yaml:"ignore"`
+	Workers int      `yaml:"workers"`
+	// CacheDir is the lint cache dir relative to the repo root, see RepoCacheDir for the default
+	CacheDir string `yaml:"cache_dir"`
+}
+
+// LoadConfig reads and validates a lint config file
+func LoadConfig(p string) (*Config, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %v", err)
+	}
+	var cfg Config
+	err = yaml.Unmarshal(b, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config %s: %v", p, err)
+	}
+	for _, g := range cfg.Ignore {
+		if _, err = path.Match(g, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignore glob %q in %s: %v", g, p, err)
+		}
+	}
+	if cfg.Workers < 0 {
+		return nil, fmt.Errorf("invalid workers in %s: %d", p, cfg.Workers)
+	}
+	return &cfg, nil
+}
+
+// LoadRepoConfig loads the config at p, or when p is empty,
+// DefaultConfigFile from the repo root if it exists
+func LoadRepoConfig(repo *cvebaser.Repo, p string) (*Config, error) {
+	if p != "" {
+		return LoadConfig(p)
+	}
+	p = repo.GetFullPath(DefaultConfigFile)
+	exists, err := cvebaser.Exists(p)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &Config{}, nil
+	}
+	return LoadConfig(p)
+}
+
+// Apply configures the linter rules and ignored paths
+func (cfg *Config) Apply(lr *Linter) error {
+	lr.initRules()
+	reg := lr.Rules
+
+	for _, id := range cfg.Rules.Enable {
+		if err := reg.Enable(id); err != nil {
+			return err
+		}
+	}
+	for _, id := range cfg.Rules.Disable {
+		if err := reg.Disable(id); err != nil {
+			return err
+		}
+	}
+	for id, s := range cfg.Rules.Severity {
+		if err := reg.SetSeverity(id, s); err != nil {
+			return err
+		}
+	}
+
+	if rule, ok := reg.Lookup(RuleCVERequired); ok {
+		if r, ok := rule.(*CVERequiredRule); ok {
+			if cfg.Required.References != nil {
+				r.RequireReference = *cfg.Required.References
+			}
+			r.RequireAdvisory = cfg.Required.Advisory
+		}
+	}
+	if rule, ok := reg.Lookup(RuleCVEPocHost); ok {
+		if r, ok := rule.(*PocHostRule); ok {
+			r.Allow = cfg.PocHosts.Allow
+			r.Deny = cfg.PocHosts.Deny
+		}
+	}
+
+	lr.Ignore = append(lr.Ignore, cfg.Ignore...)
+	return nil
+}
+
+// WorkerCount returns the configured number of workers, or DefaultWorkers
+func (cfg *Config) WorkerCount() int {
+	if cfg.Workers > 0 {
+		return cfg.Workers
+	}
+	return DefaultWorkers
+}
+
+// matchGlob reports whether slash separated path name matches pattern.
+// Each path segment is matched with path.Match, and a ** segment
+// matches zero or more segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,108 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/cvebase/cvebaser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvebaser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := path.Join(dir, DefaultConfigFile)
+	err = ioutil.WriteFile(p, []byte(`
+rules:
+  enable: [unknown-key]
+  disable: [cve-shared]
+  severity:
+    researcher-cves: error
+required:
+  references: false
+  advisory: true
+poc_hosts:
+  deny: [example.com]
+ignore:
+  - cve/1999/**
+workers: 4
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadRepoConfig(&cvebaser.Repo{DirPath: dir}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, cfg.WorkerCount())
+
+	lr := &Linter{}
+	err = cfg.Apply(lr)
+	assert.NoError(t, err)
+	assert.True(t, lr.Rules.Enabled(RuleUnknownKey))
+	assert.False(t, lr.Rules.Enabled(RuleCVEShared))
+	rule, _ := lr.Rules.Lookup(RuleResearcherCVEs)
+	assert.Equal(t, SeverityError, lr.Rules.Severity(rule))
+	rule, _ = lr.Rules.Lookup(RuleCVERequired)
+	assert.Equal(t, &CVERequiredRule{RequireReference: false, RequireAdvisory: true}, rule)
+	rule, _ = lr.Rules.Lookup(RuleCVEPocHost)
+	assert.Equal(t, []string{"example.com"}, rule.(*PocHostRule).Deny)
+	assert.True(t, lr.ignored("cve/1999/0xxx/CVE-1999-0001.md"))
+	assert.False(t, lr.ignored("cve/2020/14xxx/CVE-2020-14882.md"))
+}
+
+func TestLoadRepoConfig_Missing(t *testing.T) {
+	cfg, err := LoadRepoConfig(&cvebaser.Repo{DirPath: "does-not-exist"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultWorkers, cfg.WorkerCount())
+
+	// References are required by default so that empty cve files aren't merged
+	lr := &Linter{}
+	assert.NoError(t, cfg.Apply(lr))
+	rule, _ := lr.Rules.Lookup(RuleCVERequired)
+	assert.Equal(t, &CVERequiredRule{RequireReference: true}, rule)
+
+	_, err = LoadRepoConfig(&cvebaser.Repo{DirPath: "does-not-exist"}, "does-not-exist.yaml")
+	assert.Error(t, err)
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"cve/**", "cve/2020/14xxx/CVE-2020-14882.md", true},
+		{"cve/2020/**", "cve/2020/14xxx/CVE-2020-14882.md", true},
+		{"cve/2019/**", "cve/2020/14xxx/CVE-2020-14882.md", false},
+		{"cve/*/14xxx/*.md", "cve/2020/14xxx/CVE-2020-14882.md", true},
+		{"**/CVE-2020-14882.md", "cve/2020/14xxx/CVE-2020-14882.md", true},
+		{"researcher/*.md", "researcher/orange.md", true},
+		{"researcher/*", "researcher/sub/orange.md", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchGlob(tt.pattern, tt.name), tt.pattern)
+	}
+}
+
+func TestPocHostRule(t *testing.T) {
+	rule := &PocHostRule{Allow: []string{"github.com"}, Deny: []string{"gist.github.com"}}
+	reg, err := NewRegistry(rule)
+	assert.NoError(t, err)
+
+	c := NewCollector()
+	f := &File{Path: "cve/2020/14xxx/CVE-2020-14882.md"}
+	cve := cvebaser.CVE{Pocs: []string{
+		"https://github.com/x/y",
+		"https://gist.github.com/u/1",
+		"https://example.com/poc",
+	}}
+	rule.CheckCVE(f, &cve, reg.reporter(c, rule, f))
+	assert.Equal(t, 2, c.Len())
+}
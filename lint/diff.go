@@ -0,0 +1,34 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// unifiedDiff returns a git style unified diff between the original and
+// compiled file contents of repo relative path p
+func unifiedDiff(p string, a, b []byte) (string, error) {
+	d, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: "a/" + p,
+		ToFile:   "b/" + p,
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error generating diff for %s: %v", p, err)
+	}
+	return d, nil
+}
+
+// splitLines splits content into lines keeping line endings.
+// Unlike difflib.SplitLines, no empty line is added after a final newline.
+func splitLines(b []byte) []string {
+	lines := strings.SplitAfter(string(b), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,193 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Severity indicates how serious a lint finding is
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText encodes severity by name for json and yaml output
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes severity by name
+func (s *Severity) UnmarshalText(b []byte) error {
+	v, err := ParseSeverity(string(b))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// ParseSeverity converts a severity name e.g. "warning" to Severity
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity: %s", name)
+}
+
+// Finding is a single problem reported by a lint check
+type Finding struct {
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"` // relative to repo root e.g. cve/2020/14xxx/CVE-2020-14882.md
+	Line     int      `json:"line,omitempty"`
+	Field    string   `json:"field,omitempty"`
+	Message  string   `json:"message"`
+	Fix      string   `json:"fix,omitempty"` // optional suggested fix
+}
+
+func (f Finding) String() string {
+	loc := f.Path
+	if f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	s := fmt.Sprintf("[%s]\t%s: %s (%s)", f.Severity, loc, f.Message, f.RuleID)
+	if f.Fix != "" {
+		s = fmt.Sprintf("%s; fix: %s", s, f.Fix)
+	}
+	return s
+}
+
+// Collector gathers findings from concurrent lint workers
+type Collector struct {
+	mu       sync.Mutex
+	findings []Finding
+	diffs    []FileDiff
+	files    map[string]struct{}
+}
+
+// FileDiff is a unified diff of the changes lint would make to a file
+type FileDiff struct {
+	Path string `json:"path"`
+	Diff string `json:"diff"`
+}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Add records a finding; safe for concurrent use
+func (c *Collector) Add(f Finding) {
+	c.mu.Lock()
+	c.findings = append(c.findings, f)
+	c.mu.Unlock()
+}
+
+// Findings returns a copy of collected findings ordered by path and rule
+func (c *Collector) Findings() []Finding {
+	c.mu.Lock()
+	out := make([]Finding, len(c.findings))
+	copy(out, c.findings)
+	c.mu.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].RuleID < out[j].RuleID
+	})
+	return out
+}
+
+// AddDiff records a unified diff for a file; safe for concurrent use
+func (c *Collector) AddDiff(d FileDiff) {
+	c.mu.Lock()
+	c.diffs = append(c.diffs, d)
+	c.mu.Unlock()
+}
+
+// Diffs returns a copy of collected diffs ordered by path
+func (c *Collector) Diffs() []FileDiff {
+	c.mu.Lock()
+	out := make([]FileDiff, len(c.diffs))
+	copy(out, c.diffs)
+	c.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// AddFile records a repo relative path as linted; safe for concurrent use
+func (c *Collector) AddFile(p string) {
+	c.mu.Lock()
+	if c.files == nil {
+		c.files = make(map[string]struct{})
+	}
+	c.files[p] = struct{}{}
+	c.mu.Unlock()
+}
+
+// Files returns the sorted paths of linted files
+func (c *Collector) Files() []string {
+	c.mu.Lock()
+	out := make([]string, 0, len(c.files))
+	for p := range c.files {
+		out = append(out, p)
+	}
+	c.mu.Unlock()
+
+	sort.Strings(out)
+	return out
+}
+
+// merge adds the findings and diffs collected by other to c
+func (c *Collector) merge(other *Collector) {
+	other.mu.Lock()
+	findings, diffs := other.findings, other.diffs
+	other.mu.Unlock()
+
+	c.mu.Lock()
+	c.findings = append(c.findings, findings...)
+	c.diffs = append(c.diffs, diffs...)
+	c.mu.Unlock()
+}
+
+// Len returns the number of collected findings
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.findings)
+}
+
+// CountAtLeast returns the number of findings with severity s or higher
+func (c *Collector) CountAtLeast(s Severity) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for _, f := range c.findings {
+		if f.Severity >= s {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,42 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+)
+
+// Formatter writes collected lint findings to w in an output format
+type Formatter interface {
+	Format(w io.Writer, c *Collector, reg *Registry) error
+}
+
+// Formats lists the names accepted by NewFormatter
+var Formats = []string{"text", "sarif", "github", "junit"}
+
+// NewFormatter returns the Formatter for the named output format
+func NewFormatter(name string) (Formatter, error) {
+	switch name {
+	case "", "text":
+		return textFormatter{}, nil
+	case "sarif":
+		return sarifFormatter{}, nil
+	case "github":
+		return githubFormatter{}, nil
+	case "junit":
+		return junitFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unknown format: %s", name)
+}
+
+// textFormatter writes one line per finding e.g.
+// [warning]	cve/2016/0xxx/CVE-2020-14883.md: invalid dir for CVE-2020-14883 (cve-path)
+type textFormatter struct{}
+
+func (textFormatter) Format(w io.Writer, c *Collector, _ *Registry) error {
+	for _, f := range c.Findings() {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
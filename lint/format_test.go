@@ -0,0 +1,104 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFindings = []Finding{
+	{
+		RuleID:   RuleCVEPath,
+		Severity: SeverityWarning,
+		Path:     "cve/2016/0xxx/CVE-2020-14883.md",
+		Message:  "invalid dir for CVE-2020-14883",
+		Fix:      "move to cve/2020/14xxx/CVE-2020-14883.md",
+	},
+	{
+		RuleID:   RuleResearcherCVEID,
+		Severity: SeverityError,
+		Path:     "researcher/orange.md",
+		Line:     6,
+		Field:    "cves",
+		Message:  `invalid CVE ID "cve-2019-1234"`,
+	},
+}
+
+// testCollector returns a collector of testFindings with an additional clean file
+func testCollector() *Collector {
+	c := NewCollector()
+	for _, f := range testFindings {
+		c.AddFile(f.Path)
+		c.Add(f)
+	}
+	c.AddFile("cve/2020/14xxx/CVE-2020-14882.md")
+	return c
+}
+
+func TestTextFormatter(t *testing.T) {
+	var b bytes.Buffer
+	err := textFormatter{}.Format(&b, testCollector(), DefaultRegistry())
+	assert.NoError(t, err)
+	want := "[warning]\tcve/2016/0xxx/CVE-2020-14883.md: invalid dir for CVE-2020-14883 (cve-path); fix: move to cve/2020/14xxx/CVE-2020-14883.md\n" +
+		"[error]\tresearcher/orange.md:6: invalid CVE ID \"cve-2019-1234\" (researcher-cve-id)\n"
+	assert.Equal(t, want, b.String())
+}
+
+func TestSarifFormatter(t *testing.T) {
+	var b bytes.Buffer
+	err := sarifFormatter{}.Format(&b, testCollector(), DefaultRegistry())
+	assert.NoError(t, err)
+
+	var got sarifLog
+	err = json.Unmarshal(b.Bytes(), &got)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.1.0", got.Version)
+
+	run := got.Runs[0]
+	assert.Len(t, run.Results, 2)
+	for _, res := range run.Results {
+		assert.Equal(t, res.RuleID, run.Tool.Driver.Rules[res.RuleIndex].ID)
+	}
+	assert.Nil(t, run.Results[0].Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "error", run.Results[1].Level)
+	assert.Equal(t, "researcher/orange.md", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 6, run.Results[1].Locations[0].PhysicalLocation.Region.StartLine)
+}
+
+func TestLineOfKey(t *testing.T) {
+	content := []byte("---\nname: Orange Tsai\nalias: orange\ncves:\n  - CVE-2020-14882\n---\ncves: in body\n")
+	assert.Equal(t, 4, lineOfKey(content, "cves"))
+	assert.Equal(t, 2, lineOfKey(content, "name"))
+	assert.Equal(t, 0, lineOfKey(content, "twitter"))
+}
+
+func TestGithubFormatter(t *testing.T) {
+	var b bytes.Buffer
+	err := githubFormatter{}.Format(&b, testCollector(), DefaultRegistry())
+	assert.NoError(t, err)
+	want := "::warning file=cve/2016/0xxx/CVE-2020-14883.md,title=cve-path::invalid dir for CVE-2020-14883; fix: move to cve/2020/14xxx/CVE-2020-14883.md\n" +
+		"::error file=researcher/orange.md,line=6,title=researcher-cve-id::invalid CVE ID \"cve-2019-1234\"\n"
+	assert.Equal(t, want, b.String())
+}
+
+func TestJunitFormatter(t *testing.T) {
+	var b bytes.Buffer
+	err := junitFormatter{}.Format(&b, testCollector(), DefaultRegistry())
+	assert.NoError(t, err)
+
+	var got junitTestSuites
+	err = xml.Unmarshal(b.Bytes(), &got)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, got.Tests)
+	assert.Equal(t, 2, got.Failures)
+
+	cases := got.Suites[0].TestCases
+	assert.Equal(t, "cve/2016/0xxx/CVE-2020-14883.md", cases[0].Name)
+	assert.Equal(t, "cve-path", cases[0].Failure.Type)
+	assert.Equal(t, "cve/2020/14xxx/CVE-2020-14882.md", cases[1].Name)
+	assert.Nil(t, cases[1].Failure)
+	assert.Equal(t, "researcher", cases[2].ClassName)
+}
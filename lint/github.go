@@ -0,0 +1,57 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// githubFormatter writes findings as GitHub Actions workflow commands,
+// which show as inline annotations on pull requests e.g.
+// ::warning file=researcher/orange.md,line=6,title=researcher-cve-id::invalid CVE ID
+type githubFormatter struct{}
+
+func (githubFormatter) Format(w io.Writer, c *Collector, _ *Registry) error {
+	for _, f := range c.Findings() {
+		props := []string{"file=" + escapeGithubProperty(f.Path)}
+		if f.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", f.Line))
+		}
+		props = append(props, "title="+escapeGithubProperty(f.RuleID))
+
+		msg := f.Message
+		if f.Fix != "" {
+			msg += "; fix: " + f.Fix
+		}
+		_, err := fmt.Fprintf(w, "::%s %s::%s\n", githubLevel(f.Severity), strings.Join(props, ","), escapeGithubData(msg))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubLevel maps severity to a workflow command
+func githubLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "notice"
+}
+
+// escapeGithubData escapes a workflow command message
+func escapeGithubData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeGithubProperty escapes a workflow command property value
+func escapeGithubProperty(s string) string {
+	s = escapeGithubData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,147 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/cvebase/cvebaser"
+)
+
+// indexEntry is what the repo rules need to know about a parsed file.
+// It's recorded during the per-file pass and cached with the file's findings,
+// so repo rules don't parse every file again.
+type indexEntry struct {
+	Kind string `json:"kind"`
+	// ID is the document ID e.g. CVE ID or researcher alias
+	ID string `json:"id,omitempty"`
+	// CVEs are the CVE IDs listed by a researcher
+	CVEs []string `json:"cves,omitempty"`
+	// CVEsLine is the line of the cves key in a researcher file
+	CVEsLine int `json:"cves_line,omitempty"`
+}
+
+func newIndexEntry(doc cvebaser.Document, content []byte) indexEntry {
+	e := indexEntry{Kind: doc.Kind(), ID: doc.ID()}
+	if r, ok := doc.(*cvebaser.Researcher); ok {
+		e.CVEs = r.CVEs
+		e.CVEsLine = lineOfKey(content, "cves")
+	}
+	return e
+}
+
+// repoIndex holds the indexEntry of every parsed file, keyed by repo relative path
+type repoIndex struct {
+	mu      sync.Mutex
+	entries map[string]indexEntry
+}
+
+func newRepoIndex() *repoIndex {
+	return &repoIndex{entries: make(map[string]indexEntry)}
+}
+
+// add records the entry for file p. No-op on a nil index.
+func (idx *repoIndex) add(p string, e indexEntry) {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	idx.entries[p] = e
+	idx.mu.Unlock()
+}
+
+// move renames the entry of a file moved from one path to another
+func (idx *repoIndex) move(from, to string) {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	if e, ok := idx.entries[from]; ok {
+		delete(idx.entries, from)
+		idx.entries[to] = e
+	}
+	idx.mu.Unlock()
+}
+
+// cveIDs returns the id of every cve file, keyed by path
+func (idx *repoIndex) cveIDs() map[string]string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	ids := make(map[string]string)
+	for p, e := range idx.entries {
+		if e.Kind == "cve" {
+			ids[p] = e.ID
+		}
+	}
+	return ids
+}
+
+// researchers returns every researcher file sorted by path
+func (idx *repoIndex) researchers() []researcherRef {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	var refs []researcherRef
+	for p, e := range idx.entries {
+		if e.Kind == "researcher" {
+			refs = append(refs, researcherRef{path: p, cves: e.CVEs, cvesLine: e.CVEsLine})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].path < refs[j].path
+	})
+	return refs
+}
+
+// researcherRef is a researcher file's repo relative path and listed CVEs
+type researcherRef struct {
+	path     string
+	cves     []string
+	cvesLine int
+}
+
+// repoIndex returns the index built by LintAll, or when rules run outside of
+// LintAll, builds one by parsing every document in the repo.
+// Files that fail to parse are skipped as they're reported by the per-file lint.
+func (lr *Linter) repoIndex(ctx context.Context) (*repoIndex, error) {
+	if lr.index != nil {
+		return lr.index, nil
+	}
+
+	idx := newRepoIndex()
+	for _, kind := range cvebaser.DocumentKinds() {
+		paths, errStream := lr.ScanTree(ctx.Done(), kind, ".md")
+		for p := range paths {
+			doc, content, err := lr.parseFile(p, kind)
+			if err != nil {
+				return nil, err
+			}
+			if doc != nil {
+				idx.add(lr.relPath(p), newIndexEntry(doc, content))
+			}
+		}
+		if err := <-errStream; err != nil {
+			return nil, fmt.Errorf("error scanning %s files: %v", kind, err)
+		}
+	}
+	return idx, nil
+}
+
+// parseFile reads and parses a document of the given kind from full path p.
+// The returned doc is nil if the file fails to parse.
+func (lr *Linter) parseFile(p, kind string) (cvebaser.Document, []byte, error) {
+	content, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading %s: %v", p, err)
+	}
+	doc, err := cvebaser.NewDocument(kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = cvebaser.ParseMDFile(bytes.NewReader(content), doc); err != nil {
+		return nil, content, nil
+	}
+	return doc, content, nil
+}
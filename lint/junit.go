@@ -0,0 +1,133 @@
+package lint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitFormatter writes a JUnit XML report with one testcase per linted file.
+// A file fails when it has warning or error findings; info findings are
+// included as system-out.
+type junitFormatter struct{}
+
+func (junitFormatter) Format(w io.Writer, c *Collector, _ *Registry) error {
+	byPath := make(map[string][]Finding)
+	for _, f := range c.Findings() {
+		byPath[f.Path] = append(byPath[f.Path], f)
+	}
+	// Include files with findings from repo rules that weren't linted directly
+	paths := c.Files()
+	linted := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		linted[p] = true
+	}
+	for p := range byPath {
+		if !linted[p] {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	suite := junitTestSuite{Name: "cvebaser lint"}
+	for _, p := range paths {
+		tc := junitTestCase{
+			ClassName: strings.SplitN(p, "/", 2)[0],
+			Name:      p,
+		}
+		var failed, info []string
+		var failedRules []string
+		for _, f := range byPath[p] {
+			line := findingLine(f)
+			if f.Severity >= SeverityWarning {
+				failed = append(failed, line)
+				if !containsString(failedRules, f.RuleID) {
+					failedRules = append(failedRules, f.RuleID)
+				}
+			} else {
+				info = append(info, line)
+			}
+		}
+		if len(failed) > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d lint finding(s)", len(failed)),
+				Type:    strings.Join(failedRules, ","),
+				Text:    strings.Join(failed, "\n"),
+			}
+			suite.Failures++
+		}
+		tc.SystemOut = strings.Join(info, "\n")
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err := enc.Encode(junitTestSuites{
+		Name:     "cvebaser",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// findingLine formats a finding for a JUnit failure body e.g.
+// [error] line 6: invalid CVE ID "cve-2019-1234" (researcher-cve-id)
+func findingLine(f Finding) string {
+	s := fmt.Sprintf("[%s] ", f.Severity)
+	if f.Line > 0 {
+		s += fmt.Sprintf("line %d: ", f.Line)
+	}
+	s += fmt.Sprintf("%s (%s)", f.Message, f.RuleID)
+	if f.Fix != "" {
+		s += "; fix: " + f.Fix
+	}
+	return s
+}
+
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
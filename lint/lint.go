@@ -1,20 +1,51 @@
 package lint
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/cvebase/cvebaser"
-	"github.com/daehee/nvd"
 )
 
 type Linter struct {
 	*cvebaser.Repo
 	Stats *Stats
+	// Check reports files that would be rewritten instead of writing them
+	Check bool
+	// Diff collects a unified diff for each file that would be rewritten; implies Check
+	Diff bool
+	// FixPaths moves misplaced cve and researcher files to their correct directory
+	FixPaths bool
+	// ScaffoldCVEs creates stub cve files for CVEs listed by researchers that have no cve file.
+	// Stubs have no references, so fail cve-required until they're filled in.
+	ScaffoldCVEs bool
+	// Ignore is repo relative path globs of files to skip, see Config
+	Ignore []string
+	// Rules is the set of lint rules to run; defaults to DefaultRegistry
+	Rules *Registry
+	// Cache skips files unchanged since they were last linted, when set
+	Cache *Cache
+
+	mu    sync.Mutex
+	moves []pathMove
+	// index is built by LintAll for the repo rules
+	index *repoIndex
+	// source reads files from the git index or a commit instead of the working tree, when set.
+	// Such files are only checked, never written or moved.
+	source cvebaser.FileReader
+}
+
+// pathMove is a pending rename of a misplaced file, relative to the repo root
+type pathMove struct {
+	rule string
+	from string
+	to   string
 }
 
 func (lr *Linter) Start() {
@@ -26,48 +57,115 @@ func (lr *Linter) Start() {
 	}
 }
 
-func (lr *Linter) End() {
+// End records the finish time and tallies findings collected by c, which may be nil
+func (lr *Linter) End(c *Collector) {
 	lr.Stats.FinishedAt = time.Now()
+	if c != nil {
+		lr.Stats.CountFindings(c.Findings())
+	}
 }
 
-func (lr *Linter) LintCommit(commit string) (err error) {
+// LintCommit lints files modified in the given commit and
+// returns the collected findings
+func (lr *Linter) LintCommit(ctx context.Context, commit string) (*Collector, error) {
 	files, err := lr.CheckFilenamesFromCommit(commit)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return lr.LintFiles(ctx, files)
+}
+
+// LintRange lints the final version of files added, modified or renamed by
+// commits in the range base..head, and returns the collected findings.
+// Files are read from the tree of head, whatever is checked out, so are only
+// checked as if Check were set.
+func (lr *Linter) LintRange(ctx context.Context, base, head string) (*Collector, error) {
+	files, err := lr.FilenamesFromRange(base, head)
+	if err != nil {
+		return nil, err
+	}
+	read, err := lr.RevisionReader(head)
+	if err != nil {
+		return nil, err
+	}
+	return lr.lintFilesFrom(ctx, files, read)
+}
+
+// LintStaged lints the content staged in the git index of files added or
+// modified for the next commit, and returns the collected findings.
+// Staged content is only checked, as if Check were set, since writing it to
+// the working tree would overwrite unstaged changes.
+func (lr *Linter) LintStaged(ctx context.Context) (*Collector, error) {
+	files, err := lr.StagedFilenames()
+	if err != nil {
+		return nil, err
+	}
+	read, err := lr.IndexReader()
+	if err != nil {
+		return nil, err
 	}
+	return lr.lintFilesFrom(ctx, files, read)
+}
+
+// lintFilesFrom lints the given repo relative paths, reading their content with read
+func (lr *Linter) lintFilesFrom(ctx context.Context, files []string, read cvebaser.FileReader) (*Collector, error) {
+	lr.source = read
+	defer func() { lr.source = nil }()
+	return lr.LintFiles(ctx, files)
+}
+
+// LintFiles lints the given repo relative paths and returns the collected findings.
+// Paths that aren't documents e.g. cve or researcher files are skipped.
+// Linting stops between files once ctx is canceled.
+func (lr *Linter) LintFiles(ctx context.Context, files []string) (*Collector, error) {
+	lr.initRules()
+	c := NewCollector()
+	// Moves are only queued for files that were fully linted, so apply them even if canceled
+	defer lr.applyMoves(c)
 
 	for _, p := range files {
-		pType, err := cvebaser.PathIsType(p)
-		if err != nil {
-			return err
+		if err := ctx.Err(); err != nil {
+			return c, err
 		}
 
-		switch pType {
-		case "cve":
-			err = lintCVE(lr.GetFullPath(p))
-			if err != nil {
-				log.Print(err)
-			}
-		case "researcher":
-			err = lintResearcher(lr.GetFullPath(p))
-			if err != nil {
-				log.Print(err)
-			}
-		default:
-			return fmt.Errorf("unknown path type: %s", p)
+		kind, err := cvebaser.PathIsType(p)
+		if err != nil {
+			continue
+		}
+		doc, err := cvebaser.NewDocument(kind)
+		if err != nil {
+			return c, err
+		}
+		err = lr.lintDocument(c, lr.GetFullPath(p), doc)
+		if err != nil {
+			return c, err
 		}
 	}
 
-	return nil
+	return c, nil
 }
 
-// LintAll is the concurrent variation of LintAll
-func (lr *Linter) LintAll(concurrency int) error {
-	done := make(chan struct{})
-	defer close(done)
+// LintAll concurrently lints all documents in the repo, walking the dir of
+// each document kind, and returns the collected findings.
+// Errors from every file walk and worker are returned together as a MultiError.
+// Once ctx is canceled, workers finish the file they are on and ctx.Err() is returned.
+func (lr *Linter) LintAll(ctx context.Context, concurrency int) (*Collector, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	walkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var walks []kindWalk
+	for _, kind := range cvebaser.DocumentKinds() {
+		paths, errStream := lr.ScanTree(walkCtx.Done(), kind, ".md")
+		walks = append(walks, kindWalk{kind: kind, paths: paths, errStream: errStream})
+	}
 
-	cvePaths, errStream := lr.ScanTree(done, "cve", ".md")
-	researcherPaths, errStream := lr.ScanTree(done, "researcher", ".md")
+	lr.initRules()
+	c := NewCollector()
+	lr.index = newRepoIndex()
+	defer func() { lr.index = nil }()
 
 	// Start a number of goroutines to read and lint files.
 	errWorkerStream := make(chan error)
@@ -75,8 +173,9 @@ func (lr *Linter) LintAll(concurrency int) error {
 	wg.Add(concurrency)
 	for i := 0; i < concurrency; i++ {
 		go func() {
-			lintConcurrent(done, cvePaths, lintCVE, errWorkerStream)
-			lintConcurrent(done, researcherPaths, lintResearcher, errWorkerStream)
+			for _, w := range walks {
+				lintConcurrent(walkCtx, w.paths, lr.lintKind(c, w.kind), errWorkerStream)
+			}
 			wg.Done()
 		}()
 	}
@@ -85,168 +184,307 @@ func (lr *Linter) LintAll(concurrency int) error {
 		close(errWorkerStream)
 	}()
 
-	// Check for error thrown off by worker stream
+	// Collect errors thrown off by every worker
+	var errs MultiError
 	for err := range errWorkerStream {
 		if err != nil {
-			// TODO if err type indicates file rename, execute file move
-			fmt.Printf("[error]\t%s\n", err)
+			errs = append(errs, err)
 		}
 	}
 
-	// Check whether the file walk failed
-	if err := <-errStream; err != nil {
-		return err
+	// Stop walks still running if workers returned early, then check whether any failed
+	cancel()
+	var walkErrs MultiError
+	for _, w := range walks {
+		if err := <-w.errStream; err != nil {
+			walkErrs = append(walkErrs, fmt.Errorf("error scanning %s files: %v", w.kind, err))
+		}
 	}
 
-	return nil
+	// Moves are staged in the git index, so run them serially once workers are done
+	lr.applyMoves(c)
+
+	if err := ctx.Err(); err != nil {
+		return c, err
+	}
+	errs = append(errs, walkErrs...)
+	if len(errs) > 0 {
+		return c, errs
+	}
+
+	// Run whole repo checks once all files are linted
+	for _, rule := range lr.Rules.RepoRules() {
+		err := rule.CheckRepo(ctx, lr, lr.Rules.reporter(c, rule, nil))
+		if err != nil {
+			if ctx.Err() != nil {
+				return c, ctx.Err()
+			}
+			return c, fmt.Errorf("error running rule %s: %v", rule.ID(), err)
+		}
+	}
+
+	return c, nil
+}
+
+// MultiError is a list of errors from concurrent lint workers and file walks
+type MultiError []error
+
+func (me MultiError) Error() string {
+	msgs := make([]string, len(me))
+	for i, err := range me {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// kindWalk is a file walk of the dir of a document kind
+type kindWalk struct {
+	kind      string
+	paths     <-chan string
+	errStream <-chan error
 }
 
 // scanFn is a callback function used for per-file operation while directory scanning
 type scanFn func(string) error
 
+// lintKind returns a scanFn linting files as documents of the given kind
+func (lr *Linter) lintKind(c *Collector, kind string) scanFn {
+	return func(p string) error {
+		doc, err := cvebaser.NewDocument(kind)
+		if err != nil {
+			return err
+		}
+		return lr.lintDocument(c, p, doc)
+	}
+}
+
 // lintConcurrent is an abstracted concurrent linter function that
-// accepts a linter scanFn for either lintCVE or lintResearcher
-func lintConcurrent(done <-chan struct{}, paths <-chan string, lint scanFn, errStream chan<- error) {
+// accepts a linter scanFn, e.g. from lintKind.
+// Files are never abandoned part way, so a canceled ctx only stops new files being linted.
+func lintConcurrent(ctx context.Context, paths <-chan string, lint scanFn, errStream chan<- error) {
 	for p := range paths {
+		if ctx.Err() != nil {
+			return
+		}
 		err := lint(p)
 		select {
 		case errStream <- err:
-		case <-done:
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func lintCVE(p string) (err error) {
-	f, err := os.OpenFile(p, os.O_RDWR, 0755)
-	if err != nil {
-		return fmt.Errorf("error opening %s", p)
+// lintDocument checks and normalizes a single file, parsed into the empty doc,
+// reporting problems to c.
+// Returned errors are operational failures e.g. file could not be written.
+func (lr *Linter) lintDocument(c *Collector, p string, doc cvebaser.Document) (err error) {
+	relPath := lr.relPath(p)
+	if lr.ignored(relPath) {
+		return lr.indexIgnored(p, doc.Kind())
 	}
-	defer f.Close()
 
-	var cve cvebaser.CVE
-	err = cvebaser.ParseMDFile(f, &cve)
+	lr.Stats.IncrementScanned()
+	content, err := lr.readFile(p)
 	if err != nil {
-		return fmt.Errorf("error parsing cve file: %s: %v", cvePathToRelPath(p), err)
+		lr.Stats.IncrementFailed()
+		return fmt.Errorf("error reading %s: %v", p, err)
 	}
-
-	// Check CVE ID is correct
-	if !nvd.IsCVEID(cve.CVEID) {
-		return fmt.Errorf("invalid CVE ID %s: %s", cve.CVEID, cvePathToRelPath(p))
+	c.AddFile(relPath)
+	if e, ok := lr.Cache.replay(c, relPath, content); ok {
+		lr.index.add(relPath, e)
+		lr.Stats.IncrementCached()
+		lr.Stats.IncrementSuccessful()
+		return nil
 	}
 
-	// Check CVE directory structure
-	if !isValidCVESubPath(cve.CVEID, p) {
-		wantPath, _ := cvebaser.CVESubPath(cve.CVEID)
-		fmt.Printf("[warn]\tinvalid dir for %s: got %s; want %s\n", cve.CVEID, cvePathToRelPath(p), wantPath)
+	err = cvebaser.ParseMDFile(bytes.NewReader(content), doc)
+	if err != nil {
+		c.Add(Finding{
+			RuleID:   RuleParse,
+			Severity: SeverityError,
+			Path:     relPath,
+			Message:  fmt.Sprintf("error parsing %s file: %v", doc.Kind(), err),
+		})
+		lr.Stats.IncrementParseFailures()
+		return nil
 	}
 
-	// deduplicate values
-	cve.Pocs = cvebaser.SortUniqStrings(cve.Pocs)
-	cve.Writeups = cvebaser.SortUniqStrings(cve.Writeups)
-	cve.Courses = cvebaser.SortUniqStrings(cve.Courses)
+	// Findings for the file are collected separately so they can be cached
+	fc := NewCollector()
+	f := &File{Path: relPath, Content: content}
+	lr.Rules.checkDocument(fc, f, doc)
+	lr.queueMove(f)
 
-	// TODO check required keys
-
-	err = cvebaser.CompileToFile(f, p, cve)
+	changed, err := lr.writeFile(fc, p, content, doc)
+	c.merge(fc)
 	if err != nil {
-		return fmt.Errorf("error compiling cve file: %v", err)
+		lr.Stats.IncrementFailed()
+		return fmt.Errorf("error compiling %s file: %v", doc.Kind(), err)
 	}
+	e := newIndexEntry(doc, content)
+	lr.index.add(relPath, e)
+	lr.cacheResult(f, fc, e, changed)
+	lr.Stats.IncrementSuccessful()
 	return nil
 }
 
-func lintResearcher(p string) (err error) {
-	f, err := os.OpenFile(p, os.O_RDWR, 0755)
-	if err != nil {
-		return fmt.Errorf("error opening %s", p)
+// readFile reads full path p from the working tree, or from git when linting staged content or a range
+func (lr *Linter) readFile(p string) ([]byte, error) {
+	if lr.source != nil {
+		return lr.source(lr.relPath(p))
 	}
-	defer f.Close()
+	return ioutil.ReadFile(p)
+}
 
-	var researcher cvebaser.Researcher
-	err = cvebaser.ParseMDFile(f, &researcher)
-	if err != nil {
-		return fmt.Errorf("error parsing cve file: %v", err)
-	}
+// readOnly reports whether files are only checked, not written or moved
+func (lr *Linter) readOnly() bool {
+	return lr.Check || lr.Diff || lr.source != nil
+}
 
-	// Check researcher directory structure
-	if !isValidResearcherSubPath(researcher.Alias, p) {
-		wantPath := cvebaser.ResearcherSubPath(researcher.Alias)
-		fmt.Printf("[warn]\tinvalid dir for %s: got %s; want %s\n", researcher.Alias, researcherPathToRelPath(p), wantPath)
+// writeFile compiles the normalized document and writes it to p
+// if it differs from the original file content, reporting whether it differs.
+// In check mode the file is left untouched and a format finding is reported instead.
+func (lr *Linter) writeFile(c *Collector, p string, content []byte, doc cvebaser.Document) (changed bool, err error) {
+	if !lr.readOnly() {
+		changed, err = cvebaser.CompileToFile(p, doc)
+		if err != nil {
+			return false, err
+		}
+		if changed {
+			lr.Stats.IncrementRewritten()
+		}
+		return changed, nil
 	}
 
-	// deduplicate values
-	researcher.CVEs = cvebaser.SortUniqStrings(researcher.CVEs)
-
-	// check required keys
-	if len(researcher.CVEs) == 0 {
-		fmt.Printf("[warn]\tno CVEs defined for %s: %s\n", researcher.Alias, researcherPathToRelPath(p))
+	out, err := cvebaser.Compile(doc)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(content, out) {
+		return false, nil
 	}
 
-	// TODO regex validate website, social profiles
-
-	// check each CVE ID if valid format
-	for _, v := range researcher.CVEs {
-		if !nvd.IsCVEID(v) {
-			fmt.Printf("[warn]\tinvalid CVE ID %s: %s", v, researcherPathToRelPath(p))
+	lr.Stats.IncrementRewritten()
+	relPath := lr.relPath(p)
+	c.Add(Finding{
+		RuleID:   RuleFormat,
+		Severity: SeverityWarning,
+		Path:     relPath,
+		Message:  "file would be reformatted",
+	})
+	if lr.Diff {
+		d, err := unifiedDiff(relPath, content, out)
+		if err != nil {
+			return true, err
 		}
+		c.AddDiff(FileDiff{Path: relPath, Diff: d})
 	}
+	return true, nil
+}
 
-	err = cvebaser.CompileToFile(f, p, researcher)
-	if err != nil {
-		return fmt.Errorf("error compiling researcher file: %v", err)
+// cacheResult caches the findings and index entry of a file that needs no changes,
+// since linting it again would give the same result
+func (lr *Linter) cacheResult(f *File, fc *Collector, e indexEntry, changed bool) {
+	if changed || f.moveTo != "" {
+		lr.Cache.forget(f.Path)
+		return
 	}
-	return nil
+	lr.Cache.store(f.Path, f.Content, fc.Findings(), e)
+}
 
+// indexIgnored adds an ignored file to the index without linting it,
+// since the repo rules still see it e.g. as the cve file of a researcher's CVE
+func (lr *Linter) indexIgnored(p, kind string) error {
+	if lr.index == nil {
+		return nil
+	}
+	doc, content, err := lr.parseFile(p, kind)
+	if err != nil || doc == nil {
+		return err
+	}
+	lr.index.add(lr.relPath(p), newIndexEntry(doc, content))
+	return nil
 }
 
-// cvePathToRelPath truncates cve filepath to relative path starting with year subdirectory
-func cvePathToRelPath(p string) string {
-	// ../../../../cvebase.com/cve/2018/0xxx/CVE-2018-0142.md ->
-	// 2018/0xxx/CVE-2018-0142.md
-	splitPath := strings.Split(p, "/")
-	return strings.Join(splitPath[len(splitPath)-3:], "/")
+// initRules sets the default rules if none were configured
+func (lr *Linter) initRules() {
+	if lr.Rules == nil {
+		lr.Rules = DefaultRegistry()
+	}
 }
 
-// researcherPathToRelPath truncates researcher file path to relative path
-func researcherPathToRelPath(p string) string {
-	splitPath := strings.Split(p, "/")
-	return strings.Join(splitPath[len(splitPath)-1:], "/")
+// queueMove records a file a rule requested be moved, to be executed once linting is done.
+// No-op unless FixPaths is set and files are being written.
+func (lr *Linter) queueMove(f *File) {
+	if f.moveTo == "" || !lr.FixPaths || lr.readOnly() {
+		return
+	}
+	lr.mu.Lock()
+	lr.moves = append(lr.moves, pathMove{rule: f.moveRule, from: f.Path, to: f.moveTo})
+	lr.mu.Unlock()
 }
 
-// isValidCVESubPath checks if cve file is placed in correct year and sequence sub-directories.
-func isValidCVESubPath(cveID, path string) bool {
-	// Truncate path to slice containing relative path
-	splitPath := strings.Split(path, "/")
-	// ../../../../cvebase.com/cve/2018/xxx/CVE-2018-0142.md ->
-	// [2018, xxx, CVE-2018-0142.md]
-	splitPath = splitPath[len(splitPath)-3:]
+// applyMoves executes queued file moves, reporting any that fail to c
+func (lr *Linter) applyMoves(c *Collector) {
+	lr.mu.Lock()
+	moves := lr.moves
+	lr.moves = nil
+	lr.mu.Unlock()
 
-	validPath, err := cvebaser.CVESubPath(cveID)
-	if err != nil {
-		return false
+	for _, m := range moves {
+		err := lr.MoveFile(m.from, m.to)
+		if err != nil {
+			c.Add(Finding{
+				RuleID:   m.rule,
+				Severity: SeverityError,
+				Path:     m.from,
+				Message:  fmt.Sprintf("unable to move file: %v", err),
+			})
+			continue
+		}
+		lr.index.move(m.from, m.to)
 	}
-	splitValid := strings.Split(validPath, "/")
+}
 
-	// Compare equality of slice values
-	for i, v := range splitPath {
-		if v != splitValid[i] {
-			return false
+// ignored reports whether repo relative path p matches an Ignore glob
+func (lr *Linter) ignored(p string) bool {
+	for _, g := range lr.Ignore {
+		if matchGlob(g, p) {
+			return true
 		}
 	}
+	return false
+}
 
-	return true
+// relPath converts a full file path to a path relative to the repo root
+func (lr *Linter) relPath(p string) string {
+	rel, err := filepath.Rel(lr.DirPath, p)
+	if err != nil {
+		return p
+	}
+	return filepath.ToSlash(rel)
 }
 
-// isValidResearcherSubPath checks if researcher is placed in correct researcher subdirectory
-func isValidResearcherSubPath(rAlias, path string) bool {
+// isValidSubPath checks if a document file is placed in its correct sub-directories
+// e.g. the year and sequence dirs of a cve file
+func isValidSubPath(doc cvebaser.Document, path string) bool {
+	validPath, err := doc.SubPath()
+	if err != nil {
+		return false
+	}
+	splitValid := strings.Split(validPath, "/")
+
 	// Truncate path to slice containing relative path
+	// ../../../../cvebase.com/cve/2018/xxx/CVE-2018-0142.md ->
+	// [2018, xxx, CVE-2018-0142.md]
 	splitPath := strings.Split(path, "/")
-	// ../../../../cvebase.com/researcher/orange.md
-	// becomes
-	// [orange.md]
-	splitPath = splitPath[len(splitPath)-1:]
-	splitValid := []string{cvebaser.ResearcherSubPath(rAlias)}
+	if len(splitPath) < len(splitValid) {
+		return false
+	}
+	splitPath = splitPath[len(splitPath)-len(splitValid):]
 
+	// Compare equality of slice values
 	for i, v := range splitPath {
 		if v != splitValid[i] {
 			return false
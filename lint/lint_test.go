@@ -1,6 +1,9 @@
 package lint
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/cvebase/cvebaser"
@@ -14,10 +17,13 @@ func TestRepo_LintAll(t *testing.T) {
 	}
 	linter := &Linter{Repo: repo}
 
-	err = linter.LintAll(20)
+	c, err := linter.LintAll(context.Background(), 20)
 	if err != nil {
 		t.Fatal(err)
 	}
+	for _, f := range c.Findings() {
+		t.Log(f)
+	}
 
 }
 
@@ -27,7 +33,7 @@ func TestRepo_LintCommit(t *testing.T) {
 		t.Fatal(err)
 	}
 	linter := &Linter{Repo: repo}
-	err = linter.LintCommit("78cce2905f6a0b24cb24adbb46e922653627faf0")
+	_, err = linter.LintCommit(context.Background(), "78cce2905f6a0b24cb24adbb46e922653627faf0")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -35,6 +41,191 @@ func TestRepo_LintCommit(t *testing.T) {
 }
 
 func TestIsValidCVEDirPath(t *testing.T) {
-	got := isValidCVESubPath("CVE-2016-0974", "../../../../cvebase.com/cve/2016/0xxx/CVE-2016-0974.md")
+	got := isValidSubPath(&cvebaser.CVE{CVEID: "CVE-2016-0974"}, "../../../../cvebase.com/cve/2016/0xxx/CVE-2016-0974.md")
 	assert.True(t, got)
 }
+
+func TestCollector_Findings(t *testing.T) {
+	c := NewCollector()
+	c.Add(Finding{RuleID: RuleCVEPath, Path: "cve/2020/14xxx/CVE-2020-14882.md"})
+	c.Add(Finding{RuleID: RuleResearcherCVEs, Path: "researcher/orange.md"})
+	c.Add(Finding{RuleID: RuleCVEID, Path: "cve/2020/14xxx/CVE-2020-14882.md"})
+
+	got := c.Findings()
+	assert.Len(t, got, 3)
+	assert.Equal(t, RuleCVEID, got[0].RuleID)
+	assert.Equal(t, RuleCVEPath, got[1].RuleID)
+	assert.Equal(t, "researcher/orange.md", got[2].Path)
+}
+
+func TestCollector_CountAtLeast(t *testing.T) {
+	c := NewCollector()
+	c.Add(Finding{RuleID: RuleCVESortUniq, Severity: SeverityInfo})
+	c.Add(Finding{RuleID: RuleCVEPath, Severity: SeverityWarning})
+	c.Add(Finding{RuleID: RuleCVEID, Severity: SeverityError})
+
+	assert.Equal(t, 3, c.CountAtLeast(SeverityInfo))
+	assert.Equal(t, 2, c.CountAtLeast(SeverityWarning))
+	assert.Equal(t, 1, c.CountAtLeast(SeverityError))
+	assert.Equal(t, 0, NewCollector().CountAtLeast(SeverityInfo))
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		name string
+		want Severity
+	}{
+		{"info", SeverityInfo},
+		{"warn", SeverityWarning},
+		{"Warning", SeverityWarning},
+		{"error", SeverityError},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSeverity(tt.name)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := ParseSeverity("fatal")
+	assert.Error(t, err)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := []byte("---\nid: CVE-2020-14882\npocs:\n- https://github.com/x/y\n---\n")
+	b := []byte("---\nid: CVE-2020-14882\npocs:\n  - https://github.com/x/y\n---\n")
+	want := `--- a/cve/2020/14xxx/CVE-2020-14882.md
++++ b/cve/2020/14xxx/CVE-2020-14882.md
+@@ -1,5 +1,5 @@
+ ---
+ id: CVE-2020-14882
+ pocs:
+-- https://github.com/x/y
++  - https://github.com/x/y
+ ---
+`
+	got, err := unifiedDiff("cve/2020/14xxx/CVE-2020-14882.md", a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestLinter_Stats(t *testing.T) {
+	lr := newTestLinter(t, map[string]string{
+		"cve/2020/14xxx/CVE-2020-14882.md": "---\nid: CVE-2020-14882\npocs:\n- https://github.com/x/y2\n---\n",
+		"cve/2020/14xxx/CVE-2020-14883.md": "---\nid: CVE-2020-14883\npocs:\n  - https://github.com/x/y3\n---\n",
+		"researcher/orange.md":             "---\nname: [\n---\n",
+	})
+	defer os.RemoveAll(lr.DirPath)
+	lr.Check = true
+
+	lr.Start()
+	c, err := lr.LintAll(context.Background(), 2)
+	lr.End(c)
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, 3, lr.Stats.Scanned)
+	assert.EqualValues(t, 1, lr.Stats.Rewritten)
+	assert.EqualValues(t, 2, lr.Stats.Successful)
+	assert.EqualValues(t, 1, lr.Stats.Failed)
+	assert.EqualValues(t, 1, lr.Stats.ParseFailures)
+	assert.Equal(t, 1, lr.Stats.FindingsByRule[RuleParse])
+	assert.Equal(t, 1, lr.Stats.FindingsByRule[RuleFormat])
+	assert.Equal(t, 1, lr.Stats.FindingsBySeverity["error"])
+}
+
+func TestLinter_LintAll_Errors(t *testing.T) {
+	// Both walks fail without cve or researcher dirs
+	lr := newTestLinter(t, nil)
+	defer os.RemoveAll(lr.DirPath)
+
+	_, err := lr.LintAll(context.Background(), 2)
+	assert.Error(t, err)
+	if assert.IsType(t, MultiError{}, err) {
+		assert.Len(t, err, 2)
+	}
+	assert.Contains(t, err.Error(), "error scanning cve files")
+	assert.Contains(t, err.Error(), "error scanning researcher files")
+}
+
+func TestLinter_LintAll_Canceled(t *testing.T) {
+	content := "---\nid: CVE-2020-14882\npocs:\n- https://github.com/jas502n/CVE-2020-14882\n---\n"
+	lr := newTestLinter(t, map[string]string{
+		"cve/2020/14xxx/CVE-2020-14882.md": content,
+		"researcher/orange.md":             "---\nname: Orange Tsai\nalias: orange\n---\n",
+	})
+	defer os.RemoveAll(lr.DirPath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := lr.LintAll(ctx, 2)
+	assert.Equal(t, context.Canceled, err)
+
+	got, err := ioutil.ReadFile(lr.GetFullPath("cve/2020/14xxx/CVE-2020-14882.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(got))
+}
+
+func TestLinter_LintStaged(t *testing.T) {
+	p := "cve/2020/14xxx/CVE-2020-14882.md"
+	lr := newTestLinter(t, map[string]string{
+		p: "---\nid: cve-2020-14882\n---\n",
+	})
+	defer os.RemoveAll(lr.DirPath)
+
+	w := initTestGitRepo(t, lr)
+	if _, err := w.Add(p); err != nil {
+		t.Fatal(err)
+	}
+	// Unstaged changes aren't being committed, so aren't linted
+	worktree := "---\nid: CVE-2020-14882\n---\n"
+	if err := ioutil.WriteFile(lr.GetFullPath(p), []byte(worktree), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lr.Start()
+	c, err := lr.LintStaged(context.Background())
+	assert.NoError(t, err)
+
+	var rules []string
+	for _, f := range c.Findings() {
+		rules = append(rules, f.RuleID)
+	}
+	assert.Contains(t, rules, RuleCVEID)
+	assert.Contains(t, rules, RuleFormat)
+
+	// The working tree is left untouched
+	got, err := ioutil.ReadFile(lr.GetFullPath(p))
+	assert.NoError(t, err)
+	assert.Equal(t, worktree, string(got))
+}
+
+func TestLinter_LintRange(t *testing.T) {
+	p := "cve/2020/14xxx/CVE-2020-14882.md"
+	lr := newTestLinter(t, map[string]string{
+		p: "---\nid: CVE-2020-14882\n---\n",
+	})
+	defer os.RemoveAll(lr.DirPath)
+
+	w := initTestGitRepo(t, lr)
+	base := testCommit(t, lr, w, map[string]string{p: "---\nid: CVE-2020-14882\n---\n"})
+	head := testCommit(t, lr, w, map[string]string{p: "---\nid: cve-2020-14882\n---\n"})
+	// The working tree isn't at head
+	worktree := "---\nid: CVE-2020-14882\npocs:\n  - https://github.com/x/y\n---\n"
+	if err := ioutil.WriteFile(lr.GetFullPath(p), []byte(worktree), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lr.Start()
+	c, err := lr.LintRange(context.Background(), base, head)
+	assert.NoError(t, err)
+
+	var rules []string
+	for _, f := range c.Findings() {
+		rules = append(rules, f.RuleID)
+	}
+	assert.Contains(t, rules, RuleCVEID)
+
+	got, err := ioutil.ReadFile(lr.GetFullPath(p))
+	assert.NoError(t, err)
+	assert.Equal(t, worktree, string(got))
+}
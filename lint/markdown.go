@@ -0,0 +1,266 @@
+package lint
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cvebase/cvebaser"
+)
+
+// markdownRule checks the markdown body of cve advisories and researcher bios.
+// check returns the body with any safe fixes applied.
+type markdownRule struct {
+	id          string
+	description string
+	severity    Severity
+	check       func(body *markdownBody, rep *Reporter) string
+}
+
+func (r markdownRule) ID() string          { return r.id }
+func (r markdownRule) Description() string { return r.description }
+func (r markdownRule) Severity() Severity  { return r.severity }
+
+func (r markdownRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	cve.Advisory = r.run(f, "advisory", cve.Advisory, rep)
+}
+
+func (r markdownRule) CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter) {
+	researcher.Bio = r.run(f, "bio", researcher.Bio, rep)
+}
+
+func (r markdownRule) run(f *File, field, body string, rep *Reporter) string {
+	if strings.TrimSpace(body) == "" {
+		return body
+	}
+	return r.check(newMarkdownBody(f, field, body), rep)
+}
+
+// markdownRules returns the builtin markdown body rules in the order they run
+func markdownRules() []Rule {
+	return []Rule{
+		markdownRule{
+			id:          RuleMarkdownTrailingSpace,
+			description: "advisory and bio lines must not have trailing whitespace",
+			severity:    SeverityWarning,
+			check:       checkTrailingSpace,
+		},
+		markdownRule{
+			id:          RuleMarkdownFinalNewline,
+			description: "advisory and bio must end with a single newline",
+			severity:    SeverityWarning,
+			check:       checkFinalNewline,
+		},
+		markdownRule{
+			id:          RuleMarkdownHeading,
+			description: "advisory and bio must not use top-level # headings, which clash with the page title",
+			severity:    SeverityWarning,
+			check:       checkHeading,
+		},
+		markdownRule{
+			id:          RuleMarkdownBareURL,
+			description: "URLs in advisory and bio must be links; bare URLs are wrapped in <>",
+			severity:    SeverityInfo,
+			check:       checkBareURL,
+		},
+		markdownRule{
+			id:          RuleMarkdownLinks,
+			description: "markdown links in advisory and bio must have a valid target",
+			severity:    SeverityWarning,
+			check:       checkLinks,
+		},
+	}
+}
+
+// markdownBody is a markdown body split into lines, with code masked out
+type markdownBody struct {
+	field string
+	lines []string
+	// code marks lines inside fenced or indented code blocks
+	code []bool
+	// offset is the number of file lines before the body
+	offset int
+}
+
+func newMarkdownBody(f *File, field, body string) *markdownBody {
+	b := &markdownBody{
+		field:  field,
+		lines:  strings.Split(body, "\n"),
+		offset: frontMatterLines(f.Content),
+	}
+	b.code = make([]bool, len(b.lines))
+	var fence string
+	for i, l := range b.lines {
+		t := strings.TrimSpace(l)
+		switch {
+		case fence != "":
+			b.code[i] = true
+			if strings.HasPrefix(t, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(t, "```"), strings.HasPrefix(t, "~~~"):
+			b.code[i] = true
+			fence = t[:3]
+		case strings.HasPrefix(l, "    "), strings.HasPrefix(l, "\t"):
+			b.code[i] = true
+		}
+	}
+	return b
+}
+
+// report records a finding on the 0-based body line i
+func (b *markdownBody) report(rep *Reporter, i int, message, fix string) {
+	f := Finding{Field: b.field, Message: message, Fix: fix}
+	if b.offset > 0 {
+		f.Line = b.offset + i + 1
+	}
+	rep.Add(f)
+}
+
+func (b *markdownBody) String() string {
+	return strings.Join(b.lines, "\n")
+}
+
+// frontMatterLines returns the number of lines up to and including the
+// closing front matter delimiter, or 0 if content has no front matter
+func frontMatterLines(content []byte) int {
+	lines := strings.Split(string(content), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i, l := range lines[1:] {
+		if strings.TrimSpace(l) == "---" {
+			return i + 2
+		}
+	}
+	return 0
+}
+
+func checkTrailingSpace(b *markdownBody, rep *Reporter) string {
+	for i, l := range b.lines {
+		// Whitespace in code blocks may be significant e.g. in PoC payloads
+		if b.code[i] {
+			continue
+		}
+		t := strings.TrimRight(l, " \t\r")
+		// Two trailing spaces are a markdown hard line break
+		if t == l || (t != "" && l == t+"  ") {
+			continue
+		}
+		b.report(rep, i, "trailing whitespace", "remove trailing whitespace")
+		b.lines[i] = t
+	}
+	return b.String()
+}
+
+func checkFinalNewline(b *markdownBody, rep *Reporter) string {
+	body := b.String()
+	want := strings.TrimRight(body, "\n") + "\n"
+	switch {
+	case body == want:
+	case !strings.HasSuffix(body, "\n"):
+		b.report(rep, len(b.lines)-1, "missing final newline", "add final newline")
+	default:
+		b.report(rep, len(strings.Split(want, "\n"))-1, "trailing blank lines", "remove trailing blank lines")
+	}
+	return want
+}
+
+var rxTopHeading = regexp.MustCompile(`^ {0,3}#(\s|$)`)
+
+func checkHeading(b *markdownBody, rep *Reporter) string {
+	for i, l := range b.lines {
+		if !b.code[i] && rxTopHeading.MatchString(l) {
+			b.report(rep, i, "top-level heading clashes with page title", "use ## heading")
+		}
+	}
+	return b.String()
+}
+
+var (
+	rxCodeSpan   = regexp.MustCompile("`+[^`]*`+")
+	rxLink       = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)]*)\)`)
+	rxAutolink   = regexp.MustCompile(`<[^<>\s]+>`)
+	rxHTMLTag    = regexp.MustCompile(`</?[A-Za-z][^<>]*>`)
+	rxRefDef     = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:.*$`)
+	rxBareURL    = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+	urlTrailPunc = ".,;:!?*_~"
+)
+
+// mask replaces matches of rx in s with spaces, keeping byte offsets
+func mask(s string, rx *regexp.Regexp) string {
+	return rx.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.Repeat(" ", len(m))
+	})
+}
+
+func checkBareURL(b *markdownBody, rep *Reporter) string {
+	for i, l := range b.lines {
+		if b.code[i] {
+			continue
+		}
+		m := l
+		for _, rx := range []*regexp.Regexp{rxCodeSpan, rxLink, rxAutolink, rxHTMLTag, rxRefDef} {
+			m = mask(m, rx)
+		}
+		locs := rxBareURL.FindAllStringIndex(m, -1)
+		// Wrap from the end so earlier offsets stay valid
+		for j := len(locs) - 1; j >= 0; j-- {
+			start, end := locs[j][0], locs[j][1]
+			u := strings.TrimRight(l[start:end], urlTrailPunc)
+			end = start + len(u)
+			b.report(rep, i, fmt.Sprintf("bare URL %s", u), fmt.Sprintf("<%s>", u))
+			l = l[:start] + "<" + u + ">" + l[end:]
+		}
+		b.lines[i] = l
+	}
+	return b.String()
+}
+
+func checkLinks(b *markdownBody, rep *Reporter) string {
+	for i, l := range b.lines {
+		if b.code[i] {
+			continue
+		}
+		for _, sm := range rxLink.FindAllStringSubmatch(mask(l, rxCodeSpan), -1) {
+			target := strings.TrimSpace(sm[2])
+			// Drop optional link title e.g. [x](https://example.com "title")
+			if fields := strings.Fields(target); len(fields) > 0 {
+				target = strings.Trim(fields[0], "<>")
+			}
+			if target == "" {
+				b.report(rep, i, fmt.Sprintf("empty link target for [%s]", sm[1]), "")
+				continue
+			}
+			if err := validLinkTarget(target); err != nil {
+				b.report(rep, i, fmt.Sprintf("broken link %q: %v", target, err), "")
+			}
+		}
+	}
+	return b.String()
+}
+
+// validLinkTarget checks a link target is an absolute http(s) or mailto URL,
+// a path on cvebase.com or an anchor
+func validLinkTarget(target string) error {
+	if strings.HasPrefix(target, "#") || (strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//")) {
+		return nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("missing host")
+		}
+	case "mailto":
+	case "":
+		return fmt.Errorf("relative link")
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cvebase/cvebaser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownRules(t *testing.T) {
+	in := "---\nid: CVE-2020-14882\n---\n" +
+		"# CVE-2020-14882 \n" +
+		"Line with hard break  \n" +
+		"See https://example.com/advisory. and <https://example.com/ok>\n" +
+		"[empty]() and [broken](www.example.com) and [ok](https://example.com \"title\")\n" +
+		"```\n# not a heading https://example.com/code \t\n```\n" +
+		"`https://example.com/span` end\t\n\n\n"
+
+	var cve cvebaser.CVE
+	err := cvebaser.ParseMDFile(bytes.NewReader([]byte(in)), &cve)
+	assert.NoError(t, err)
+
+	reg, err := NewRegistry(markdownRules()...)
+	assert.NoError(t, err)
+
+	c := NewCollector()
+	f := &File{Path: "cve/2020/14xxx/CVE-2020-14882.md", Content: []byte(in)}
+	for _, rule := range reg.CVERules() {
+		rule.CheckCVE(f, &cve, reg.reporter(c, rule, f))
+	}
+
+	want := "# CVE-2020-14882\n" +
+		"Line with hard break  \n" +
+		"See <https://example.com/advisory>. and <https://example.com/ok>\n" +
+		"[empty]() and [broken](www.example.com) and [ok](https://example.com \"title\")\n" +
+		"```\n# not a heading https://example.com/code \t\n```\n" +
+		"`https://example.com/span` end\n"
+	assert.Equal(t, want, cve.Advisory)
+
+	got := make(map[string][]int)
+	for _, f := range c.Findings() {
+		got[f.RuleID] = append(got[f.RuleID], f.Line)
+	}
+	assert.Equal(t, map[string][]int{
+		RuleMarkdownTrailingSpace: {4, 11},
+		RuleMarkdownFinalNewline:  {12},
+		RuleMarkdownHeading:       {4},
+		RuleMarkdownBareURL:       {6},
+		RuleMarkdownLinks:         {7, 7},
+	}, got)
+}
+
+func TestMarkdownRules_EmptyBody(t *testing.T) {
+	reg, err := NewRegistry(markdownRules()...)
+	assert.NoError(t, err)
+
+	c := NewCollector()
+	f := &File{Path: "researcher/orange.md"}
+	researcher := cvebaser.Researcher{Alias: "orange"}
+	for _, rule := range reg.ResearcherRules() {
+		rule.CheckResearcher(f, &researcher, reg.reporter(c, rule, f))
+	}
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, "", researcher.Bio)
+}
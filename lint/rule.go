@@ -0,0 +1,291 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cvebase/cvebaser"
+)
+
+// Rule is a named lint check. A rule hooks into linting by implementing
+// one or more of CVERule, ResearcherRule and RepoRule.
+//
+// Custom rules can be added to a registry and passed to a Linter:
+//
+//	rules := lint.DefaultRegistry()
+//	rules.Register(myRule)
+//	linter := &lint.Linter{Repo: repo, Rules: rules}
+type Rule interface {
+	// ID is the unique identifier used to enable or disable the rule e.g. "cve-path"
+	ID() string
+	// Description is a short human readable summary of what the rule checks
+	Description() string
+	// Severity is the default severity of findings reported by the rule
+	Severity() Severity
+}
+
+// CVERule checks a single cve document. Rules may modify the document
+// to normalize values, and changes are written back by the linter.
+type CVERule interface {
+	Rule
+	CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter)
+}
+
+// ResearcherRule checks a single researcher document. Rules may modify the document
+// to normalize values, and changes are written back by the linter.
+type ResearcherRule interface {
+	Rule
+	CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter)
+}
+
+// RepoRule performs a check across the whole repo, after all files are linted.
+type RepoRule interface {
+	Rule
+	CheckRepo(ctx context.Context, lr *Linter, rep *Reporter) error
+}
+
+// File identifies the file being linted
+type File struct {
+	// Path is relative to repo root e.g. cve/2020/14xxx/CVE-2020-14882.md
+	Path string
+	// Content is the file contents as read from disk, before any changes
+	Content []byte
+
+	moveTo   string
+	moveRule string
+}
+
+// Reporter records findings for a rule.
+// Findings are attributed to the rule with its configured severity.
+type Reporter struct {
+	c        *Collector
+	rule     string
+	severity Severity
+	file     *File
+}
+
+// Report records a finding for the current file
+func (r *Reporter) Report(field, message string) {
+	r.Add(Finding{Field: field, Message: message})
+}
+
+// Reportf records a finding for the current file with a formatted message
+func (r *Reporter) Reportf(field, format string, args ...interface{}) {
+	r.Report(field, fmt.Sprintf(format, args...))
+}
+
+// ReportFix records a finding for the current file with a suggested fix
+func (r *Reporter) ReportFix(field, message, fix string) {
+	r.Add(Finding{Field: field, Message: message, Fix: fix})
+}
+
+// Add records a finding, setting its rule ID and severity from the rule.
+// Path defaults to the current file when not set, and Line to the line
+// of the front matter key Field in the current file.
+func (r *Reporter) Add(f Finding) {
+	f.RuleID = r.rule
+	f.Severity = r.severity
+	if f.Path == "" && r.file != nil {
+		f.Path = r.file.Path
+	}
+	if f.Line == 0 && f.Field != "" && r.file != nil && f.Path == r.file.Path {
+		f.Line = lineOfKey(r.file.Content, f.Field)
+	}
+	r.c.Add(f)
+}
+
+// Move requests the current file be moved to repo relative path to.
+// The move is only executed when the linter is fixing paths.
+func (r *Reporter) Move(to string) {
+	if r.file == nil || r.file.Path == to {
+		return
+	}
+	r.file.moveTo = to
+	r.file.moveRule = r.rule
+}
+
+// Registry holds the set of lint rules and which are enabled
+type Registry struct {
+	rules    []Rule
+	disabled map[string]bool
+	severity map[string]Severity
+}
+
+// NewRegistry returns a registry with the given rules enabled
+func NewRegistry(rules ...Rule) (*Registry, error) {
+	reg := &Registry{
+		disabled: make(map[string]bool),
+		severity: make(map[string]Severity),
+	}
+	for _, rule := range rules {
+		err := reg.Register(rule)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// DefaultRegistry returns a new registry containing the builtin rules,
+// with opt-in rules disabled
+func DefaultRegistry() *Registry {
+	reg, err := NewRegistry(builtinRules()...)
+	if err != nil {
+		panic(err)
+	}
+	for _, id := range optInRules {
+		reg.disabled[id] = true
+	}
+	return reg
+}
+
+// Register adds an enabled rule to the registry.
+// Rules run in the order they are registered.
+func (reg *Registry) Register(rule Rule) error {
+	if _, ok := reg.Lookup(rule.ID()); ok {
+		return fmt.Errorf("rule already registered: %s", rule.ID())
+	}
+	reg.rules = append(reg.rules, rule)
+	return nil
+}
+
+// Lookup returns the rule registered with the given ID
+func (reg *Registry) Lookup(id string) (Rule, bool) {
+	for _, rule := range reg.rules {
+		if rule.ID() == id {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// Rules returns all registered rules in registration order
+func (reg *Registry) Rules() []Rule {
+	out := make([]Rule, len(reg.rules))
+	copy(out, reg.rules)
+	return out
+}
+
+// Enable turns on a registered rule
+func (reg *Registry) Enable(id string) error {
+	if _, ok := reg.Lookup(id); !ok {
+		return fmt.Errorf("unknown rule: %s", id)
+	}
+	delete(reg.disabled, id)
+	return nil
+}
+
+// Disable turns off a registered rule
+func (reg *Registry) Disable(id string) error {
+	if _, ok := reg.Lookup(id); !ok {
+		return fmt.Errorf("unknown rule: %s", id)
+	}
+	reg.disabled[id] = true
+	return nil
+}
+
+// Enabled reports whether the rule with the given ID is registered and enabled
+func (reg *Registry) Enabled(id string) bool {
+	_, ok := reg.Lookup(id)
+	return ok && !reg.disabled[id]
+}
+
+// SetSeverity overrides the default severity of a registered rule
+func (reg *Registry) SetSeverity(id string, severity Severity) error {
+	if _, ok := reg.Lookup(id); !ok {
+		return fmt.Errorf("unknown rule: %s", id)
+	}
+	reg.severity[id] = severity
+	return nil
+}
+
+// Severity returns the severity for findings of the given rule,
+// applying any override set with SetSeverity
+func (reg *Registry) Severity(rule Rule) Severity {
+	if s, ok := reg.severity[rule.ID()]; ok {
+		return s
+	}
+	return rule.Severity()
+}
+
+// CVERules returns enabled rules that check cve documents
+func (reg *Registry) CVERules() []CVERule {
+	var out []CVERule
+	for _, rule := range reg.rules {
+		if r, ok := rule.(CVERule); ok && !reg.disabled[rule.ID()] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ResearcherRules returns enabled rules that check researcher documents
+func (reg *Registry) ResearcherRules() []ResearcherRule {
+	var out []ResearcherRule
+	for _, rule := range reg.rules {
+		if r, ok := rule.(ResearcherRule); ok && !reg.disabled[rule.ID()] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// checkDocument runs the enabled rules for the kind of doc, reporting findings for file f to c
+func (reg *Registry) checkDocument(c *Collector, f *File, doc cvebaser.Document) {
+	for _, rule := range reg.rules {
+		if reg.disabled[rule.ID()] {
+			continue
+		}
+		switch doc := doc.(type) {
+		case *cvebaser.CVE:
+			if r, ok := rule.(CVERule); ok {
+				r.CheckCVE(f, doc, reg.reporter(c, rule, f))
+			}
+		case *cvebaser.Researcher:
+			if r, ok := rule.(ResearcherRule); ok {
+				r.CheckResearcher(f, doc, reg.reporter(c, rule, f))
+			}
+		}
+	}
+}
+
+// RepoRules returns enabled rules that check the whole repo
+func (reg *Registry) RepoRules() []RepoRule {
+	var out []RepoRule
+	for _, rule := range reg.rules {
+		if r, ok := rule.(RepoRule); ok && !reg.disabled[rule.ID()] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// reporter returns a Reporter attributing findings for file f to rule
+func (reg *Registry) reporter(c *Collector, rule Rule, f *File) *Reporter {
+	return &Reporter{
+		c:        c,
+		rule:     rule.ID(),
+		severity: reg.Severity(rule),
+		file:     f,
+	}
+}
+
+// lineOfKey returns the 1-based line number of top level key in the
+// YAML front matter of content, or 0 if not found
+func lineOfKey(content []byte, key string) int {
+	lines := strings.Split(string(content), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	prefix := key + ":"
+	for i, l := range lines[1:] {
+		if strings.TrimSpace(l) == "---" {
+			break
+		}
+		if strings.HasPrefix(l, prefix) {
+			return i + 2
+		}
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/cvebase/cvebaser"
+	"github.com/stretchr/testify/assert"
+)
+
+type denyHostRule struct{}
+
+func (denyHostRule) ID() string          { return "deny-host" }
+func (denyHostRule) Description() string { return "test rule" }
+func (denyHostRule) Severity() Severity  { return SeverityError }
+
+func (denyHostRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	for _, v := range cve.Pocs {
+		if v == "https://example.com/poc" {
+			rep.Report("pocs", "denied host")
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg := DefaultRegistry()
+	n := len(reg.CVERules())
+	err := reg.Register(denyHostRule{})
+	assert.NoError(t, err)
+	err = reg.Register(denyHostRule{})
+	assert.Error(t, err)
+
+	assert.True(t, reg.Enabled("deny-host"))
+	assert.Len(t, reg.CVERules(), n+1)
+
+	err = reg.Disable("deny-host")
+	assert.NoError(t, err)
+	assert.False(t, reg.Enabled("deny-host"))
+	assert.Len(t, reg.CVERules(), n)
+
+	err = reg.Enable("no-such-rule")
+	assert.Error(t, err)
+}
+
+func TestReporter(t *testing.T) {
+	reg, err := NewRegistry(denyHostRule{})
+	assert.NoError(t, err)
+
+	c := NewCollector()
+	f := &File{Path: "cve/2020/14xxx/CVE-2020-14882.md"}
+	cve := cvebaser.CVE{CVEID: "CVE-2020-14882", Pocs: []string{"https://example.com/poc"}}
+	for _, rule := range reg.CVERules() {
+		rule.CheckCVE(f, &cve, reg.reporter(c, rule, f))
+	}
+
+	assert.Equal(t, []Finding{{
+		RuleID:   "deny-host",
+		Severity: SeverityError,
+		Path:     "cve/2020/14xxx/CVE-2020-14882.md",
+		Field:    "pocs",
+		Message:  "denied host",
+	}}, c.Findings())
+}
+
+func TestCVEPathRule(t *testing.T) {
+	reg, err := NewRegistry(cvePathRule{})
+	assert.NoError(t, err)
+
+	c := NewCollector()
+	f := &File{Path: "cve/2016/0xxx/CVE-2020-14883.md"}
+	cve := cvebaser.CVE{CVEID: "CVE-2020-14883"}
+	for _, rule := range reg.CVERules() {
+		rule.CheckCVE(f, &cve, reg.reporter(c, rule, f))
+	}
+
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, "cve/2020/14xxx/CVE-2020-14883.md", f.moveTo)
+}
+
+func TestResearcherPathRule(t *testing.T) {
+	reg, err := NewRegistry(researcherPathRule{})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		alias  string
+		moveTo string
+	}{
+		{"orange", "researcher/orange.md"},
+		{"", ""},
+		{"../../x/y", ""},
+		{".hidden", ""},
+	}
+	for _, tt := range tests {
+		c := NewCollector()
+		f := &File{Path: "researcher/other.md"}
+		researcher := cvebaser.Researcher{Alias: tt.alias}
+		for _, rule := range reg.ResearcherRules() {
+			rule.CheckResearcher(f, &researcher, reg.reporter(c, rule, f))
+		}
+		assert.Equal(t, 1, c.Len(), tt.alias)
+		assert.Equal(t, tt.moveTo, f.moveTo, tt.alias)
+	}
+}
+
+func TestUnknownKeyRule(t *testing.T) {
+	reg := DefaultRegistry()
+	assert.False(t, reg.Enabled(RuleUnknownKey))
+	err := reg.Enable(RuleUnknownKey)
+	assert.NoError(t, err)
+
+	rule, _ := reg.Lookup(RuleUnknownKey)
+	c := NewCollector()
+	f := &File{Path: "cve/2020/14xxx/CVE-2020-14882.md"}
+	cve := cvebaser.CVE{CVEID: "CVE-2020-14882", Extra: map[string]interface{}{"tags": []string{"rce"}, "cvss": 9.8}}
+	rule.(CVERule).CheckCVE(f, &cve, reg.reporter(c, rule, f))
+
+	got := c.Findings()
+	assert.Len(t, got, 2)
+	assert.Equal(t, "cvss", got[0].Field)
+	assert.Equal(t, "tags", got[1].Field)
+}
+
+func TestCVERequiredRule(t *testing.T) {
+	tests := []struct {
+		rule CVERequiredRule
+		cve  cvebaser.CVE
+		want int
+	}{
+		{CVERequiredRule{}, cvebaser.CVE{CVEID: "CVE-2020-14882"}, 0},
+		{CVERequiredRule{}, cvebaser.CVE{}, 1},
+		{CVERequiredRule{}, cvebaser.CVE{CVEID: "CVE-2020-14883"}, 1},
+		{CVERequiredRule{RequireReference: true}, cvebaser.CVE{CVEID: "CVE-2020-14882"}, 1},
+		{CVERequiredRule{RequireReference: true}, cvebaser.CVE{CVEID: "CVE-2020-14882", Courses: []string{"https://example.com"}}, 0},
+		{CVERequiredRule{RequireAdvisory: true}, cvebaser.CVE{CVEID: "CVE-2020-14882", Advisory: "\n"}, 1},
+		{CVERequiredRule{RequireAdvisory: true}, cvebaser.CVE{CVEID: "CVE-2020-14882", Advisory: "RCE\n"}, 0},
+	}
+
+	for _, tt := range tests {
+		reg, err := NewRegistry(&tt.rule)
+		assert.NoError(t, err)
+		c := NewCollector()
+		f := &File{Path: "cve/2020/14xxx/CVE-2020-14882.md"}
+		tt.rule.CheckCVE(f, &tt.cve, reg.reporter(c, &tt.rule, f))
+		assert.Equal(t, tt.want, c.Len(), "%+v", tt)
+	}
+}
+
+func TestCVEIDRules(t *testing.T) {
+	reg, err := NewRegistry(cveIDRule{}, researcherCVEIDRule{}, researcherSortUniqRule{})
+	assert.NoError(t, err)
+
+	c := NewCollector()
+	f := &File{Path: "cve/2016/1000xxx/CVE-2016-1000123.md"}
+	cve := cvebaser.CVE{CVEID: "cve-2016-01000123 "}
+	for _, rule := range reg.CVERules() {
+		rule.CheckCVE(f, &cve, reg.reporter(c, rule, f))
+	}
+	assert.Equal(t, "CVE-2016-1000123", cve.CVEID)
+	if assert.Equal(t, 1, c.Len()) {
+		assert.Equal(t, "CVE-2016-1000123", c.Findings()[0].Fix)
+	}
+
+	c = NewCollector()
+	f = &File{Path: "researcher/orange.md"}
+	researcher := cvebaser.Researcher{Alias: "orange", CVEs: []string{"CVE-2019-11510", "cve-2019-11510", "CVE-2019-123", "bogus"}}
+	for _, rule := range reg.ResearcherRules() {
+		rule.CheckResearcher(f, &researcher, reg.reporter(c, rule, f))
+	}
+	assert.Equal(t, []string{"CVE-2019-0123", "CVE-2019-11510", "bogus"}, researcher.CVEs)
+	// 2 repairs, 1 invalid, 1 duplicate removed
+	assert.Equal(t, 4, c.Len())
+}
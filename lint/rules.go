@@ -0,0 +1,302 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cvebase/cvebaser"
+	"github.com/daehee/nvd"
+)
+
+// Rule IDs for checks performed by the linter itself, outside of the registry
+const (
+	RuleParse  = "parse"
+	RuleFormat = "format"
+)
+
+// coreRule describes findings reported by the linter itself
+type coreRule struct {
+	id          string
+	description string
+	severity    Severity
+}
+
+func (r coreRule) ID() string          { return r.id }
+func (r coreRule) Description() string { return r.description }
+func (r coreRule) Severity() Severity  { return r.severity }
+
+// CoreRules returns descriptions of the parse and format checks,
+// which always run and aren't part of a Registry
+func CoreRules() []Rule {
+	return []Rule{
+		coreRule{RuleParse, "file must have valid front matter", SeverityError},
+		coreRule{RuleFormat, "file must be formatted as lint would write it", SeverityWarning},
+	}
+}
+
+// Rule IDs for builtin rules
+const (
+	RuleCVEID              = "cve-id"
+	RuleCVEPath            = "cve-path"
+	RuleCVEURLs            = "cve-urls"
+	RuleCVERequired        = "cve-required"
+	RuleCVEPocHost         = "cve-poc-host"
+	RuleCVESortUniq        = "cve-sort-uniq"
+	RuleResearcherPath     = "researcher-path"
+	RuleResearcherSortUniq = "researcher-sort-uniq"
+	RuleResearcherCVEs     = "researcher-cves"
+	RuleResearcherCVEID    = "researcher-cve-id"
+	RuleResearcherSocial   = "researcher-social"
+	RuleResearcherCVERef   = "researcher-cve-ref"
+	RuleCVEShared          = "cve-shared"
+	RuleCVEDuplicate       = "cve-duplicate"
+	RuleUnknownKey         = "unknown-key"
+
+	RuleMarkdownTrailingSpace = "markdown-trailing-space"
+	RuleMarkdownFinalNewline  = "markdown-final-newline"
+	RuleMarkdownHeading       = "markdown-heading"
+	RuleMarkdownBareURL       = "markdown-bare-url"
+	RuleMarkdownLinks         = "markdown-links"
+)
+
+// builtinRules returns the default rules in the order they run
+func builtinRules() []Rule {
+	rules := []Rule{
+		cveIDRule{},
+		cvePathRule{},
+		// URLs are canonicalized before sort so that duplicates collapse
+		cveURLsRule{},
+		cveSortUniqRule{},
+		&PocHostRule{},
+		&CVERequiredRule{RequireReference: true},
+		researcherPathRule{},
+		// IDs are repaired before sort so that duplicates collapse
+		researcherCVEIDRule{},
+		researcherSortUniqRule{},
+		researcherCVEsRule{},
+		researcherSocialRule{},
+		researcherCVERefRule{},
+		cveSharedRule{},
+		cveDuplicateRule{},
+		unknownKeyRule{},
+	}
+	return append(rules, markdownRules()...)
+}
+
+// optInRules are builtin rules disabled by default
+var optInRules = []string{
+	RuleUnknownKey,
+}
+
+// cveIDRule checks the cve front matter id is a valid CVE ID
+type cveIDRule struct{}
+
+func (cveIDRule) ID() string { return RuleCVEID }
+func (cveIDRule) Description() string {
+	return "cve id must be a valid CVE ID; repairs case, whitespace and zero-padding"
+}
+func (cveIDRule) Severity() Severity { return SeverityError }
+
+func (cveIDRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	// Missing ids are reported by cve-required
+	if cve.CVEID == "" {
+		return
+	}
+	cve.CVEID = normalizeCVEID(cve.CVEID, "id", rep)
+}
+
+// cvePathRule checks cve file is placed in correct year and sequence sub-directories
+type cvePathRule struct{}
+
+func (cvePathRule) ID() string { return RuleCVEPath }
+func (cvePathRule) Description() string {
+	return "cve file must be placed in cve/<year>/<sequence dir>/<id>.md"
+}
+func (cvePathRule) Severity() Severity { return SeverityWarning }
+
+func (cvePathRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	// Path can't be determined for invalid IDs, which are reported by cve-id
+	if !nvd.IsCVEID(cve.CVEID) {
+		return
+	}
+	if isValidSubPath(cve, f.Path) {
+		return
+	}
+	wantPath, err := cvebaser.DocumentPath(cve)
+	if err != nil {
+		return
+	}
+	rep.ReportFix("", fmt.Sprintf("invalid dir for %s", cve.CVEID), fmt.Sprintf("move to %s", wantPath))
+	rep.Move(wantPath)
+}
+
+// cveSortUniqRule sorts and removes duplicate cve references
+type cveSortUniqRule struct{}
+
+func (cveSortUniqRule) ID() string          { return RuleCVESortUniq }
+func (cveSortUniqRule) Description() string { return "sort and deduplicate pocs, writeups and courses" }
+func (cveSortUniqRule) Severity() Severity  { return SeverityInfo }
+
+func (cveSortUniqRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	cve.Pocs = sortUniqReport(cve.Pocs, "pocs", rep)
+	cve.Writeups = sortUniqReport(cve.Writeups, "writeups", rep)
+	cve.Courses = sortUniqReport(cve.Courses, "courses", rep)
+}
+
+// CVERequiredRule checks a cve has an id matching its filename, and optionally
+// that it has references and an advisory so that empty files aren't merged
+type CVERequiredRule struct {
+	// RequireReference requires at least one of pocs, writeups or courses
+	RequireReference bool
+	// RequireAdvisory requires a non-empty advisory body
+	RequireAdvisory bool
+}
+
+func (*CVERequiredRule) ID() string { return RuleCVERequired }
+func (*CVERequiredRule) Description() string {
+	return "cve must have an id matching its filename, and configured required fields"
+}
+func (*CVERequiredRule) Severity() Severity { return SeverityError }
+
+func (rule *CVERequiredRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	fileID := strings.TrimSuffix(path.Base(f.Path), path.Ext(f.Path))
+	switch {
+	case cve.CVEID == "":
+		rep.ReportFix("id", "missing id", fileID)
+	case cve.CVEID != fileID:
+		rep.Reportf("id", "id %s does not match filename %s", cve.CVEID, path.Base(f.Path))
+	}
+
+	if rule.RequireReference && len(cve.Pocs) == 0 && len(cve.Writeups) == 0 && len(cve.Courses) == 0 {
+		rep.Report("", "at least one of pocs, writeups or courses is required")
+	}
+	if rule.RequireAdvisory && strings.TrimSpace(cve.Advisory) == "" {
+		rep.Report("", "advisory is required")
+	}
+}
+
+// researcherPathRule checks researcher file is named after the researcher alias
+type researcherPathRule struct{}
+
+func (researcherPathRule) ID() string { return RuleResearcherPath }
+func (researcherPathRule) Description() string {
+	return "researcher file must be placed in researcher/<alias>.md"
+}
+func (researcherPathRule) Severity() Severity { return SeverityWarning }
+
+func (researcherPathRule) CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter) {
+	// The alias names the file, so the file can't be moved unless it's a plain name
+	alias := researcher.Alias
+	switch {
+	case alias == "":
+		rep.Report("alias", "missing alias")
+		return
+	case strings.ContainsAny(alias, `/\`) || strings.Contains(alias, "..") || strings.HasPrefix(alias, "."):
+		rep.Reportf("alias", "invalid alias %q: must not contain / or .., or start with .", alias)
+		return
+	}
+	if isValidSubPath(researcher, f.Path) {
+		return
+	}
+	wantPath, err := cvebaser.DocumentPath(researcher)
+	if err != nil {
+		return
+	}
+	rep.ReportFix("alias", fmt.Sprintf("invalid dir for %s", researcher.Alias), fmt.Sprintf("move to %s", wantPath))
+	rep.Move(wantPath)
+}
+
+// researcherSortUniqRule sorts and removes duplicate researcher CVE IDs
+type researcherSortUniqRule struct{}
+
+func (researcherSortUniqRule) ID() string          { return RuleResearcherSortUniq }
+func (researcherSortUniqRule) Description() string { return "sort and deduplicate researcher cves" }
+func (researcherSortUniqRule) Severity() Severity  { return SeverityInfo }
+
+func (researcherSortUniqRule) CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter) {
+	researcher.CVEs = sortUniqReport(researcher.CVEs, "cves", rep)
+}
+
+// researcherCVEsRule checks researcher has at least one CVE
+type researcherCVEsRule struct{}
+
+func (researcherCVEsRule) ID() string          { return RuleResearcherCVEs }
+func (researcherCVEsRule) Description() string { return "researcher must list at least one CVE" }
+func (researcherCVEsRule) Severity() Severity  { return SeverityWarning }
+
+func (researcherCVEsRule) CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter) {
+	if len(researcher.CVEs) == 0 {
+		rep.Reportf("cves", "no CVEs defined for %s", researcher.Alias)
+	}
+}
+
+// researcherCVEIDRule checks each researcher CVE ID is valid format
+type researcherCVEIDRule struct{}
+
+func (researcherCVEIDRule) ID() string { return RuleResearcherCVEID }
+func (researcherCVEIDRule) Description() string {
+	return "researcher cves must be valid CVE IDs; repairs case, whitespace and zero-padding"
+}
+func (researcherCVEIDRule) Severity() Severity { return SeverityWarning }
+
+func (researcherCVEIDRule) CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter) {
+	for i, v := range researcher.CVEs {
+		researcher.CVEs[i] = normalizeCVEID(v, "cves", rep)
+	}
+}
+
+// normalizeCVEID returns the repaired CVE ID of field,
+// reporting invalid and repaired IDs
+func normalizeCVEID(cveID, field string, rep *Reporter) string {
+	fixed, err := cvebaser.NormalizeCVEID(cveID)
+	if err != nil {
+		rep.Reportf(field, "invalid CVE ID %q", cveID)
+		return cveID
+	}
+	if fixed != cveID {
+		rep.ReportFix(field, fmt.Sprintf("malformed CVE ID %q", cveID), fixed)
+	}
+	return fixed
+}
+
+// unknownKeyRule reports front matter keys that are not part of the document model.
+// Unknown keys are otherwise preserved, so this rule is opt-in for strict linting.
+type unknownKeyRule struct{}
+
+func (unknownKeyRule) ID() string { return RuleUnknownKey }
+func (unknownKeyRule) Description() string {
+	return "front matter must only contain known keys (strict)"
+}
+func (unknownKeyRule) Severity() Severity { return SeverityWarning }
+
+func (unknownKeyRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	reportUnknownKeys(cve.Extra, rep)
+}
+
+func (unknownKeyRule) CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter) {
+	reportUnknownKeys(researcher.Extra, rep)
+}
+
+func reportUnknownKeys(extra map[string]interface{}, rep *Reporter) {
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rep.Reportf(k, "unknown front matter key %q", k)
+	}
+}
+
+// sortUniqReport sorts and deduplicates values of field,
+// reporting the number of duplicates removed
+func sortUniqReport(values []string, field string, rep *Reporter) []string {
+	n := len(values)
+	values = cvebaser.SortUniqStrings(values)
+	if removed := n - len(values); removed > 0 {
+		rep.Reportf(field, "removed %d duplicate %s", removed, field)
+	}
+	return values
+}
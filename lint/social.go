@@ -0,0 +1,164 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cvebase/cvebaser"
+)
+
+// socialProfile describes how a researcher profile field is written.
+// Profiles are stored canonically as a bare handle e.g. `orange_8361`,
+// and may be given as a full profile URL, `@handle` or bare handle.
+type socialProfile struct {
+	field  string
+	hosts  []string // profile URL hosts, without www.
+	prefix string   // path prefix before the handle in profile URLs
+	rx     *regexp.Regexp
+	value  func(*cvebaser.Researcher) *string
+}
+
+var socialProfiles = []socialProfile{
+	{
+		field: "twitter",
+		hosts: []string{"twitter.com", "mobile.twitter.com", "x.com"},
+		rx:    regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`),
+		value: func(r *cvebaser.Researcher) *string { return &r.Twitter },
+	},
+	{
+		field: "github",
+		hosts: []string{"github.com"},
+		rx:    regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`),
+		value: func(r *cvebaser.Researcher) *string { return &r.Github },
+	},
+	{
+		field:  "linkedin",
+		hosts:  []string{"linkedin.com"},
+		prefix: "in/",
+		rx:     regexp.MustCompile(`^[\pL\pN_%-]{3,100}$`),
+		value:  func(r *cvebaser.Researcher) *string { return &r.Linkedin },
+	},
+	{
+		field: "hackerone",
+		hosts: []string{"hackerone.com"},
+		rx:    regexp.MustCompile(`^[A-Za-z0-9_.-]{1,50}$`),
+		value: func(r *cvebaser.Researcher) *string { return &r.Hackerone },
+	},
+	{
+		field: "bugcrowd",
+		hosts: []string{"bugcrowd.com"},
+		rx:    regexp.MustCompile(`^[A-Za-z0-9_.-]{1,50}$`),
+		value: func(r *cvebaser.Researcher) *string { return &r.Bugcrowd },
+	},
+}
+
+// researcherSocialRule validates researcher website and social profiles,
+// normalizing profiles to a bare handle
+type researcherSocialRule struct{}
+
+func (researcherSocialRule) ID() string { return RuleResearcherSocial }
+func (researcherSocialRule) Description() string {
+	return "researcher website must be an http(s) URL; social profiles are normalized to a bare handle"
+}
+func (researcherSocialRule) Severity() Severity { return SeverityWarning }
+
+func (researcherSocialRule) CheckResearcher(f *File, researcher *cvebaser.Researcher, rep *Reporter) {
+	for _, sp := range socialProfiles {
+		v := sp.value(researcher)
+		if *v == "" {
+			continue
+		}
+		h, err := sp.normalize(*v)
+		if err != nil {
+			rep.Reportf(sp.field, "invalid %s profile %q: %v", sp.field, *v, err)
+			continue
+		}
+		if h != *v {
+			rep.ReportFix(sp.field, fmt.Sprintf("non-canonical %s profile %q", sp.field, *v), h)
+			*v = h
+		}
+	}
+
+	if researcher.Website != "" {
+		u, err := NormalizeURL(researcher.Website)
+		if err != nil {
+			rep.Reportf("website", "invalid website %q: %v", researcher.Website, err)
+			return
+		}
+		if u != researcher.Website {
+			rep.ReportFix("website", fmt.Sprintf("non-canonical website %q", researcher.Website), u)
+			researcher.Website = u
+		}
+	}
+}
+
+// NormalizeProfile returns the canonical handle for a researcher profile field
+// e.g. "twitter" given a profile URL, @handle or bare handle
+func NormalizeProfile(field, raw string) (string, error) {
+	for _, sp := range socialProfiles {
+		if sp.field == field {
+			return sp.normalize(raw)
+		}
+	}
+	return "", fmt.Errorf("unknown profile field: %s", field)
+}
+
+func (sp socialProfile) normalize(raw string) (string, error) {
+	h := strings.TrimSpace(raw)
+	if h == "" {
+		return "", errors.New("empty value")
+	}
+
+	if strings.Contains(h, "/") {
+		var err error
+		h, err = sp.handleFromURL(h)
+		if err != nil {
+			return "", err
+		}
+	}
+	h = strings.TrimPrefix(h, "@")
+
+	if !sp.rx.MatchString(h) {
+		return "", fmt.Errorf("handle %q does not match %s", h, sp.rx)
+	}
+	return h, nil
+}
+
+// handleFromURL extracts the handle from a profile URL, with or without scheme
+func (sp socialProfile) handleFromURL(s string) (string, error) {
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	var known bool
+	for _, v := range sp.hosts {
+		if host == v {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return "", fmt.Errorf("not a %s profile URL", sp.field)
+	}
+
+	p := strings.Trim(u.Path, "/")
+	if sp.prefix != "" {
+		if !strings.HasPrefix(p, sp.prefix) {
+			return "", fmt.Errorf("not a %s profile URL", sp.field)
+		}
+		p = strings.TrimPrefix(p, sp.prefix)
+	}
+	// Drop any sub-pages after the handle e.g. twitter.com/x/status/1
+	return strings.SplitN(p, "/", 2)[0], nil
+}
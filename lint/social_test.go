@@ -0,0 +1,51 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeProfile(t *testing.T) {
+	tests := []struct {
+		field string
+		raw   string
+		want  string
+	}{
+		{"twitter", "orange_8361", "orange_8361"},
+		{"twitter", "@orange_8361", "orange_8361"},
+		{"twitter", "https://twitter.com/orange_8361", "orange_8361"},
+		{"twitter", "twitter.com/orange_8361/", "orange_8361"},
+		{"twitter", "https://mobile.twitter.com/orange_8361?lang=en", "orange_8361"},
+		{"github", "https://github.com/orangetw", "orangetw"},
+		{"github", "@orangetw", "orangetw"},
+		{"linkedin", "https://www.linkedin.com/in/orange-tsai/", "orange-tsai"},
+		{"linkedin", "orange-tsai", "orange-tsai"},
+		{"hackerone", "https://hackerone.com/orange", "orange"},
+		{"bugcrowd", "bugcrowd.com/orange", "orange"},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeProfile(tt.field, tt.raw)
+		assert.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestNormalizeProfile_Invalid(t *testing.T) {
+	tests := []struct {
+		field string
+		raw   string
+	}{
+		{"twitter", "https://github.com/orangetw"},
+		{"twitter", "this handle is too long"},
+		{"github", "-orangetw"},
+		{"linkedin", "https://www.linkedin.com/company/foo"},
+		{"hackerone", "ftp://hackerone.com/orange"},
+	}
+
+	for _, tt := range tests {
+		_, err := NormalizeProfile(tt.field, tt.raw)
+		assert.Error(t, err, tt.raw)
+	}
+}
@@ -1,15 +1,35 @@
 package lint
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
 	"sync/atomic"
+	"text/tabwriter"
 	"time"
 )
 
+// Stats records counts from a lint run. Counters are safe to increment
+// concurrently, and increments on a nil Stats are no-ops.
 type Stats struct {
-	StartedAt  time.Time
-	FinishedAt time.Time
-	Successful uint32
-	Failed     uint32
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Successful is the number of files linted and written without error
+	Successful uint32 `json:"successful"`
+	// Failed is the number of files that couldn't be read, parsed or written
+	Failed uint32 `json:"failed"`
+	// Scanned is the number of files read, excluding ignored files
+	Scanned uint32 `json:"scanned"`
+	// Cached is the number of files skipped as unchanged since they were last linted
+	Cached uint32 `json:"cached"`
+	// Rewritten is the number of files rewritten, or that would be in check mode
+	Rewritten uint32 `json:"rewritten"`
+	// ParseFailures is the number of files with invalid front matter
+	ParseFailures uint32 `json:"parse_failures"`
+	// FindingsByRule and FindingsBySeverity are set by CountFindings
+	FindingsByRule     map[string]int `json:"findings_by_rule"`
+	FindingsBySeverity map[string]int `json:"findings_by_severity"`
 }
 
 func (st *Stats) Duration() time.Duration {
@@ -17,9 +37,89 @@ func (st *Stats) Duration() time.Duration {
 }
 
 func (st *Stats) IncrementSuccessful() {
+	if st == nil {
+		return
+	}
 	atomic.AddUint32(&st.Successful, 1)
 }
 
 func (st *Stats) IncrementFailed() {
+	if st == nil {
+		return
+	}
 	atomic.AddUint32(&st.Failed, 1)
 }
+
+func (st *Stats) IncrementScanned() {
+	if st == nil {
+		return
+	}
+	atomic.AddUint32(&st.Scanned, 1)
+}
+
+func (st *Stats) IncrementCached() {
+	if st == nil {
+		return
+	}
+	atomic.AddUint32(&st.Cached, 1)
+}
+
+func (st *Stats) IncrementRewritten() {
+	if st == nil {
+		return
+	}
+	atomic.AddUint32(&st.Rewritten, 1)
+}
+
+// IncrementParseFailures counts a file with invalid front matter as failed
+func (st *Stats) IncrementParseFailures() {
+	if st == nil {
+		return
+	}
+	atomic.AddUint32(&st.ParseFailures, 1)
+	atomic.AddUint32(&st.Failed, 1)
+}
+
+// CountFindings tallies findings by rule and severity
+func (st *Stats) CountFindings(findings []Finding) {
+	st.FindingsByRule = make(map[string]int)
+	st.FindingsBySeverity = make(map[string]int)
+	for _, f := range findings {
+		st.FindingsByRule[f.RuleID]++
+		st.FindingsBySeverity[f.Severity.String()]++
+	}
+}
+
+// WriteSummary writes a human readable table of the stats to w
+func (st *Stats) WriteSummary(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 1, 2, ' ', 0)
+	fmt.Fprintf(tw, "Files scanned\t%d\n", st.Scanned)
+	fmt.Fprintf(tw, "Files cached\t%d\n", st.Cached)
+	fmt.Fprintf(tw, "Files rewritten\t%d\n", st.Rewritten)
+	fmt.Fprintf(tw, "Successful\t%d\n", st.Successful)
+	fmt.Fprintf(tw, "Failed\t%d\n", st.Failed)
+	fmt.Fprintf(tw, "Parse failures\t%d\n", st.ParseFailures)
+	for _, sev := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		fmt.Fprintf(tw, "Findings (%s)\t%d\n", sev, st.FindingsBySeverity[sev.String()])
+	}
+	rules := make([]string, 0, len(st.FindingsByRule))
+	for id := range st.FindingsByRule {
+		rules = append(rules, id)
+	}
+	sort.Strings(rules)
+	for _, id := range rules {
+		fmt.Fprintf(tw, "  %s\t%d\n", id, st.FindingsByRule[id])
+	}
+	fmt.Fprintf(tw, "Time Completed\t%v\n", st.Duration().Round(time.Second))
+	return tw.Flush()
+}
+
+// WriteJSON writes the stats as a JSON object to w, including the duration in seconds
+func (st *Stats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*Stats
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{st, st.Duration().Seconds()})
+}
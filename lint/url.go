@@ -0,0 +1,169 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cvebase/cvebaser"
+)
+
+// cveURLsRule validates pocs, writeups and courses are http(s) URLs and
+// canonicalizes URLs of known hosts so that duplicates collapse
+type cveURLsRule struct{}
+
+func (cveURLsRule) ID() string { return RuleCVEURLs }
+func (cveURLsRule) Description() string {
+	return "pocs, writeups and courses must be http(s) URLs; canonicalizes github, gist and exploit-db URLs"
+}
+func (cveURLsRule) Severity() Severity { return SeverityWarning }
+
+func (cveURLsRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	normalizeURLs(cve.Pocs, "pocs", rep)
+	normalizeURLs(cve.Writeups, "writeups", rep)
+	normalizeURLs(cve.Courses, "courses", rep)
+}
+
+// normalizeURLs replaces each value of field in place with its canonical URL,
+// reporting invalid and changed values
+func normalizeURLs(values []string, field string, rep *Reporter) {
+	for i, v := range values {
+		n, err := NormalizeURL(v)
+		if err != nil {
+			rep.Reportf(field, "invalid URL %q: %v", v, err)
+			continue
+		}
+		if n != v {
+			rep.ReportFix(field, fmt.Sprintf("non-canonical URL %q", v), n)
+			values[i] = n
+		}
+	}
+}
+
+// NormalizeURL validates raw is an absolute http(s) URL and returns its canonical form.
+// Scheme and host are lowercased, and known hosts are rewritten to a single form:
+//
+//	http://www.github.com/x/y.git/ -> https://github.com/x/y
+//	https://github.com/X/Y/tree/master -> https://github.com/x/y
+//	gist.github.com/u/id/          -> https://gist.github.com/u/id
+//	http://exploit-db.com/exploits/1234/ -> https://www.exploit-db.com/exploits/1234
+func NormalizeURL(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", errors.New("empty value")
+	}
+	if strings.ContainsAny(s, " \t\n") {
+		return "", errors.New("contains whitespace")
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", errors.New("missing host")
+	}
+	u.Host = strings.ToLower(u.Host)
+
+	switch strings.TrimPrefix(u.Host, "www.") {
+	case "github.com":
+		u.Scheme = "https"
+		u.Host = "github.com"
+		u.Path = githubRepoPath(u.Path)
+	case "gist.github.com":
+		u.Scheme = "https"
+		u.Path = canonicalRepoPath(u.Path)
+	case "exploit-db.com":
+		u.Scheme = "https"
+		u.Host = "www.exploit-db.com"
+		u.Path = strings.TrimRight(u.Path, "/")
+	}
+
+	return u.String(), nil
+}
+
+// canonicalRepoPath trims trailing slashes from a github or gist path,
+// and the .git suffix from a clone URL path e.g. /x/y.git -> /x/y
+func canonicalRepoPath(p string) string {
+	p = strings.TrimRight(p, "/")
+	segments := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	if len(segments) <= 2 {
+		p = strings.TrimSuffix(p, ".git")
+	}
+	return p
+}
+
+// githubDefaultBranches are the branch names whose tree is collapsed to the repo root
+var githubDefaultBranches = []string{"master", "main"}
+
+// githubRepoPath canonicalizes a github.com path. Owner and repo names are
+// case-insensitive so they're lowercased, and the tree of a default branch with
+// no subpath is the repo root e.g. /X/Y/tree/master -> /x/y.
+// The rest of tree and blob paths are kept as file paths are case-sensitive.
+func githubRepoPath(p string) string {
+	p = canonicalRepoPath(p)
+	if p == "" {
+		return p
+	}
+	segments := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i := 0; i < len(segments) && i < 2; i++ {
+		segments[i] = strings.ToLower(segments[i])
+	}
+	if len(segments) == 4 && segments[2] == "tree" {
+		for _, b := range githubDefaultBranches {
+			if segments[3] == b {
+				segments = segments[:2]
+				break
+			}
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// PocHostRule restricts which hosts pocs may link to.
+// Hosts match themselves and their subdomains e.g. github.com matches gist.github.com.
+type PocHostRule struct {
+	// Allow, when set, is the only hosts pocs may link to
+	Allow []string
+	// Deny is hosts pocs must not link to
+	Deny []string
+}
+
+func (*PocHostRule) ID() string { return RuleCVEPocHost }
+func (*PocHostRule) Description() string {
+	return "pocs must link to allowed hosts and not to denied hosts"
+}
+func (*PocHostRule) Severity() Severity { return SeverityWarning }
+
+func (rule *PocHostRule) CheckCVE(f *File, cve *cvebaser.CVE, rep *Reporter) {
+	for _, v := range cve.Pocs {
+		u, err := url.Parse(strings.TrimSpace(v))
+		// Invalid URLs are reported by cve-urls
+		if err != nil || u.Host == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		switch {
+		case matchHost(host, rule.Deny):
+			rep.Reportf("pocs", "poc host %s is denied: %s", host, v)
+		case len(rule.Allow) > 0 && !matchHost(host, rule.Allow):
+			rep.Reportf("pocs", "poc host %s is not allowed: %s", host, v)
+		}
+	}
+}
+
+// matchHost reports whether host equals or is a subdomain of any of hosts
+func matchHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
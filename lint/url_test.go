@@ -0,0 +1,67 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://github.com/x/y", "https://github.com/x/y"},
+		{"http://github.com/x/y/", "https://github.com/x/y"},
+		{"https://github.com/x/y.git", "https://github.com/x/y"},
+		{"https://www.GitHub.com/x/y", "https://github.com/x/y"},
+		{"https://github.com/x/y/blob/master/poc.py#L10", "https://github.com/x/y/blob/master/poc.py#L10"},
+		{"https://github.com/x/y/tree/master/poc/", "https://github.com/x/y/tree/master/poc"},
+		{"https://github.com/X/Y", "https://github.com/x/y"},
+		{"https://github.com/x/y/tree/master", "https://github.com/x/y"},
+		{"https://github.com/x/y/tree/main/", "https://github.com/x/y"},
+		{"https://github.com/x/y/tree/dev", "https://github.com/x/y/tree/dev"},
+		{"https://github.com/X/Y/tree/master/PoC", "https://github.com/x/y/tree/master/PoC"},
+		{"https://github.com/X/Y/blob/master/PoC.py", "https://github.com/x/y/blob/master/PoC.py"},
+		{"https://github.com/X", "https://github.com/x"},
+		{"https://github.com/", "https://github.com"},
+		{"https://github.com", "https://github.com"},
+		{"http://gist.github.com/u/abc123/", "https://gist.github.com/u/abc123"},
+		{"http://exploit-db.com/exploits/48971/", "https://www.exploit-db.com/exploits/48971"},
+		{"https://www.exploit-db.com/exploits/48971", "https://www.exploit-db.com/exploits/48971"},
+		{" https://example.com/a/ ", "https://example.com/a/"},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeURL(tt.raw)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestNormalizeURL_GitHubDedupe(t *testing.T) {
+	for _, raw := range []string{
+		"https://github.com/X/Y",
+		"https://github.com/x/y",
+		"https://github.com/x/y/tree/master",
+	} {
+		got, err := NormalizeURL(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://github.com/x/y", got, raw)
+	}
+}
+
+func TestNormalizeURL_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"github.com/x/y",
+		"ftp://example.com/poc",
+		"javascript:alert(1)",
+		"https://example.com/a b",
+	}
+
+	for _, raw := range tests {
+		_, err := NormalizeURL(raw)
+		assert.Error(t, err, raw)
+	}
+}
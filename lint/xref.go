@@ -0,0 +1,166 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cvebase/cvebaser"
+	"github.com/daehee/nvd"
+)
+
+// researcherCVERefRule checks every CVE listed by a researcher has a cve file,
+// optionally scaffolding stub files for missing CVEs
+type researcherCVERefRule struct{}
+
+func (researcherCVERefRule) ID() string { return RuleResearcherCVERef }
+func (researcherCVERefRule) Description() string {
+	return "researcher cves must have a matching cve file"
+}
+func (researcherCVERefRule) Severity() Severity { return SeverityWarning }
+
+func (researcherCVERefRule) CheckRepo(ctx context.Context, lr *Linter, rep *Reporter) error {
+	idx, err := lr.repoIndex(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]bool)
+	for _, id := range idx.cveIDs() {
+		ids[id] = true
+	}
+
+	for _, ref := range idx.researchers() {
+		for _, id := range ref.cves {
+			// Invalid IDs are reported by researcher-cve-id
+			if !nvd.IsCVEID(id) || ids[id] {
+				continue
+			}
+			wantPath, err := cvebaser.DocumentPath(&cvebaser.CVE{CVEID: id})
+			if err != nil {
+				continue
+			}
+			rep.Add(Finding{
+				Path:    ref.path,
+				Line:    ref.cvesLine,
+				Field:   "cves",
+				Message: fmt.Sprintf("no cve file for %s", id),
+				Fix:     fmt.Sprintf("create %s", wantPath),
+			})
+
+			if lr.ScaffoldCVEs && !lr.readOnly() {
+				err = scaffoldCVE(lr, id, wantPath)
+				if err != nil {
+					return err
+				}
+				idx.add(wantPath, indexEntry{Kind: "cve", ID: id})
+				// Only scaffold once for CVEs listed by multiple researchers
+				ids[id] = true
+			}
+		}
+	}
+	return nil
+}
+
+// cveSharedRule reports CVEs listed by more than one researcher
+type cveSharedRule struct{}
+
+func (cveSharedRule) ID() string { return RuleCVEShared }
+func (cveSharedRule) Description() string {
+	return "report CVEs claimed by more than one researcher"
+}
+func (cveSharedRule) Severity() Severity { return SeverityInfo }
+
+func (cveSharedRule) CheckRepo(ctx context.Context, lr *Linter, rep *Reporter) error {
+	idx, err := lr.repoIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	claims := make(map[string][]string)
+	for _, ref := range idx.researchers() {
+		for _, id := range cvebaser.UniqStrings(ref.cves) {
+			claims[id] = append(claims[id], ref.path)
+		}
+	}
+
+	ids := make([]string, 0, len(claims))
+	for id, paths := range claims {
+		if len(paths) > 1 && nvd.IsCVEID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		cvePath, err := cvebaser.DocumentPath(&cvebaser.CVE{CVEID: id})
+		if err != nil {
+			continue
+		}
+		paths := claims[id]
+		sort.Strings(paths)
+		rep.Add(Finding{
+			Path:    cvePath,
+			Field:   "id",
+			Message: fmt.Sprintf("%s claimed by %d researchers: %s", id, len(paths), strings.Join(paths, ", ")),
+		})
+	}
+	return nil
+}
+
+// cveDuplicateRule reports CVE IDs with more than one cve file
+type cveDuplicateRule struct{}
+
+func (cveDuplicateRule) ID() string { return RuleCVEDuplicate }
+func (cveDuplicateRule) Description() string {
+	return "each CVE must have a single cve file; merge duplicates with cvebaser dedupe"
+}
+func (cveDuplicateRule) Severity() Severity { return SeverityError }
+
+func (cveDuplicateRule) CheckRepo(ctx context.Context, lr *Linter, rep *Reporter) error {
+	idx, err := lr.repoIndex(ctx)
+	if err != nil {
+		return err
+	}
+	for _, dup := range cvebaser.GroupDuplicateCVEs(idx.cveIDs()) {
+		for _, p := range dup.Paths {
+			if p == dup.Path {
+				continue
+			}
+			var others []string
+			for _, o := range dup.Paths {
+				if o != p {
+					others = append(others, o)
+				}
+			}
+			rep.Add(Finding{
+				Path:    p,
+				Field:   "id",
+				Message: fmt.Sprintf("duplicate %s also in %s", dup.CVEID, strings.Join(others, ", ")),
+				Fix:     fmt.Sprintf("merge into %s with cvebaser dedupe", dup.Path),
+			})
+		}
+	}
+	return nil
+}
+
+// scaffoldCVE writes a stub cve file containing only the CVE ID to repo relative path p
+func scaffoldCVE(lr *Linter, cveID, p string) error {
+	fullPath := lr.GetFullPath(p)
+	exists, err := cvebaser.Exists(fullPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	err = os.MkdirAll(path.Dir(fullPath), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating dir for %s: %v", p, err)
+	}
+	_, err = cvebaser.CompileToFile(fullPath, &cvebaser.CVE{CVEID: cveID})
+	return err
+}
@@ -0,0 +1,101 @@
+package lint
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cvebase/cvebaser"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestLinter writes files to a temp repo dir and returns a Linter for it.
+// Callers remove the dir with os.RemoveAll(lr.DirPath).
+func newTestLinter(t *testing.T, files map[string]string) *Linter {
+	dir, err := ioutil.TempDir("", "cvebaser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, content := range files {
+		fp := path.Join(dir, p)
+		if err = os.MkdirAll(path.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(fp, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Linter{Repo: &cvebaser.Repo{DirPath: dir}}
+}
+
+// initTestGitRepo inits a git repo in the dir of a newTestLinter, returning its worktree
+func initTestGitRepo(t *testing.T, lr *Linter) *git.Worktree {
+	gitRepo, err := git.PlainInit(lr.DirPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// testCommit writes and stages files, keyed by repo relative path, and commits them
+func testCommit(t *testing.T, lr *Linter, w *git.Worktree, files map[string]string) string {
+	for p, content := range files {
+		if err := os.MkdirAll(path.Dir(lr.GetFullPath(p)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(lr.GetFullPath(p), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h, err := w.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h.String()
+}
+
+func TestResearcherCVERefRule(t *testing.T) {
+	lr := newTestLinter(t, map[string]string{
+		"cve/2020/14xxx/CVE-2020-14882.md": "---\nid: CVE-2020-14882\n---\n",
+		// Files that fail to parse are reported by the per-file lint, not the repo rules
+		"cve/2020/1xxx/CVE-2020-1000.md": "---\nid: [\n---\n",
+		"researcher/orange.md":           "---\nname: Orange Tsai\nalias: orange\ncves:\n  - CVE-2020-14882\n  - CVE-2019-11510\n---\n",
+		"researcher/other.md":            "---\nname: Other\nalias: other\ncves:\n  - CVE-2020-14882\n---\n",
+	})
+	defer os.RemoveAll(lr.DirPath)
+	lr.ScaffoldCVEs = true
+
+	reg, err := NewRegistry(researcherCVERefRule{}, cveSharedRule{})
+	assert.NoError(t, err)
+
+	c := NewCollector()
+	for _, rule := range reg.RepoRules() {
+		err = rule.CheckRepo(context.Background(), lr, reg.reporter(c, rule, nil))
+		assert.NoError(t, err)
+	}
+
+	got := c.Findings()
+	assert.Len(t, got, 2)
+	assert.Equal(t, RuleCVEShared, got[0].RuleID)
+	assert.Equal(t, "cve/2020/14xxx/CVE-2020-14882.md", got[0].Path)
+	assert.Equal(t, RuleResearcherCVERef, got[1].RuleID)
+	assert.Equal(t, "researcher/orange.md", got[1].Path)
+
+	exists, err := cvebaser.Exists(lr.GetFullPath("cve/2019/11xxx/CVE-2019-11510.md"))
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -56,7 +57,10 @@ func (r *Repo) ScanTree(done <-chan struct{}, subDir string, fileExt string) (<-
 		// Select block not needed for this send, since errStream is buffered
 		errStream <- godirwalk.Walk(path.Join(r.DirPath, subDir), &godirwalk.Options{
 			Callback: func(osPathname string, de *godirwalk.Dirent) error {
-				if strings.Contains(osPathname, fileExt) {
+				if skip, err := skipDotfile(de); skip {
+					return err
+				}
+				if path.Ext(osPathname) == fileExt {
 					select {
 					case pathStream <- osPathname:
 					case <-done:
@@ -72,6 +76,18 @@ func (r *Repo) ScanTree(done <-chan struct{}, subDir string, fileExt string) (<-
 	return pathStream, errStream
 }
 
+// skipDotfile reports whether a walked entry is hidden e.g. a temp file being written,
+// returning filepath.SkipDir for hidden dirs
+func skipDotfile(de *godirwalk.Dirent) (bool, error) {
+	if !strings.HasPrefix(de.Name(), ".") {
+		return false, nil
+	}
+	if de.IsDir() {
+		return true, filepath.SkipDir
+	}
+	return true, nil
+}
+
 // ScanCVE returns a channel of all CVE objects in the repo.
 // A buffered error channel returns any errors encountered during the dirwalk.
 func (r *Repo) ScanCVE(ctx context.Context) (<-chan CVE, <-chan error) {
@@ -84,8 +100,11 @@ func (r *Repo) ScanCVE(ctx context.Context) (<-chan CVE, <-chan error) {
 		// Select block not needed for this send, since errStream is buffered
 		errStream <- godirwalk.Walk(path.Join(r.DirPath, "cve"), &godirwalk.Options{
 			Callback: func(osPathname string, de *godirwalk.Dirent) error {
-				if strings.Contains(osPathname, ".md") {
-					f, err := os.OpenFile(osPathname, os.O_RDWR, 0755)
+				if skip, err := skipDotfile(de); skip {
+					return err
+				}
+				if path.Ext(osPathname) == ".md" {
+					f, err := os.Open(osPathname)
 					if err != nil {
 						return fmt.Errorf("error opening %s", osPathname)
 					}
@@ -115,7 +134,6 @@ func (r *Repo) ScanCVE(ctx context.Context) (<-chan CVE, <-chan error) {
 // for cases where the file was linted and moved in a later commit.
 // Returns a relative path to cve or researcher file.
 func WantPath(p string) (string, error) {
-	var newPath string
 	pathToFileNameSansExt := func(p string) string {
 		sp := strings.Split(p, "/")
 		fileName := sp[len(sp)-1]
@@ -126,24 +144,21 @@ func WantPath(p string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	switch pType {
-	case "cve":
-		cveID := pathToFileNameSansExt(p)
-		// TODO refactor this and use for linter as well
-		if !nvd.IsCVEIDStrict(cveID) {
-			cveID = nvd.FixCVEID(cveID)
-		}
-		wdp, err := CVESubPath(cveID)
-		if err != nil {
-			return "", err
+	doc, err := NewDocument(pType)
+	if err != nil {
+		return "", err
+	}
+	// TODO parse researcher file content and grab researcher alias
+	id := pathToFileNameSansExt(p)
+	if pType == "cve" {
+		if fixed, err := NormalizeCVEID(id); err == nil {
+			id = fixed
 		}
-		newPath = path.Join("cve", wdp)
-	case "researcher":
-		// TODO parse file content and grab researcher alias
-		wdp := ResearcherSubPath(pathToFileNameSansExt(p))
-		newPath = path.Join("researcher", wdp)
-	default:
-		return "", fmt.Errorf("could not match path to CVE or Researcher type: %s", p)
+	}
+	doc.SetID(id)
+	newPath, err := DocumentPath(doc)
+	if err != nil {
+		return "", err
 	}
 
 	// if p == newPath {
@@ -153,33 +168,38 @@ func WantPath(p string) (string, error) {
 	return newPath, nil
 }
 
-// PathIsType returns either "cve" or "researcher" based on
+// PathIsType returns the document kind, either "cve" or "researcher", based on
 // directory structure of given relative path to cve or researcher file
 func PathIsType(p string) (string, error) {
-	patternTypes := []struct {
-		t string
-		p string
-	}{
-		{"cve", "cve/*/*/*.md"},
-		{"researcher", "researcher/*.md"},
-	}
-	var matchedType string
-
-	for _, v := range patternTypes {
-		matched, err := filepath.Match(v.p, p)
+	for _, k := range documentKinds {
+		matched, err := filepath.Match(k.pattern, p)
 		if err != nil {
-			return "", fmt.Errorf("error matching path %s with %s", p, v.p)
+			return "", fmt.Errorf("error matching path %s with %s", p, k.pattern)
 		}
 		if matched {
-			matchedType = v.t
+			return k.kind, nil
 		}
 	}
+	return "", fmt.Errorf("unable to match path: %s", p)
+}
 
-	if matchedType == "" {
-		return "", fmt.Errorf("unable to match path: %s", p)
-	}
+var cveIDLooseRx = regexp.MustCompile(`^CVE-\d{4}-\d+$`)
 
-	return matchedType, nil
+// NormalizeCVEID repairs a malformed CVE ID, removing whitespace, uppercasing,
+// and fixing zero-padding of the sequence number e.g. " cve-2016-01000123" -> "CVE-2016-1000123".
+// Returns an error if the ID can't be repaired.
+func NormalizeCVEID(cveID string) (string, error) {
+	fixed := strings.ToUpper(strings.Join(strings.Fields(cveID), ""))
+	if !cveIDLooseRx.MatchString(fixed) {
+		return "", fmt.Errorf("invalid CVE ID: %q", cveID)
+	}
+	if !nvd.IsCVEIDStrict(fixed) {
+		fixed = nvd.FixCVEID(fixed)
+	}
+	if !nvd.IsCVEID(fixed) {
+		return "", fmt.Errorf("invalid CVE ID: %q", cveID)
+	}
+	return fixed, nil
 }
 
 // CVESubPath converts a CVE ID to cve relative path starting with year subdirectory
@@ -2,7 +2,9 @@ package cvebaser
 
 import (
 	"context"
+	"io/ioutil"
 	"os"
+	"path"
 	"testing"
 
 	"github.com/go-git/go-git/v5"
@@ -120,6 +122,30 @@ func TestWantPath(t *testing.T) {
 
 }
 
+func TestNormalizeCVEID(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"CVE-2020-14882", "CVE-2020-14882"},
+		{"cve-2020-14882", "CVE-2020-14882"},
+		{" CVE-2020-14882\n", "CVE-2020-14882"},
+		{"CVE- 2020-14882", "CVE-2020-14882"},
+		{"CVE-2016-01000123", "CVE-2016-1000123"},
+		{"CVE-2019-123", "CVE-2019-0123"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeCVEID(tt.in)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+	}
+
+	for _, in := range []string{"", "CVE-2020", "2020-14882", "CVE-20-14882", "CVE-2020-abc"} {
+		_, err := NormalizeCVEID(in)
+		assert.Error(t, err, in)
+	}
+}
+
 func TestPathIsType(t *testing.T) {
 	tests := []struct {
 		path string
@@ -223,3 +249,36 @@ Loop:
 		t.Fatal(err)
 	}
 }
+
+func TestRepo_ScanTree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvebaser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		"cve/2020/14xxx/CVE-2020-14882.md",
+		"cve/2020/14xxx/.tmp-CVE-2020-14883.md-123",
+		"cve/2020/14xxx/.CVE-2020-14884.md",
+		"cve/2020/14xxx/CVE-2020-14885.md.orig",
+		"cve/.hidden/CVE-2020-14886.md",
+	}
+	for _, p := range files {
+		if err = os.MkdirAll(path.Join(dir, path.Dir(p)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path.Join(dir, p), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &Repo{DirPath: dir}
+	paths, errStream := r.ScanTree(nil, "cve", ".md")
+	var got []string
+	for p := range paths {
+		got = append(got, p)
+	}
+	assert.NoError(t, <-errStream)
+	assert.Equal(t, []string{path.Join(dir, "cve/2020/14xxx/CVE-2020-14882.md")}, got)
+}
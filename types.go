@@ -1,11 +1,19 @@
 package cvebaser
 
+import "gopkg.in/yaml.v3"
+
 type CVE struct {
 	CVEID    string   `json:"-" yaml:"id"`
 	Pocs     []string `json:"pocs,omitempty" yaml:"pocs,omitempty"`
 	Courses  []string `json:"courses,omitempty" yaml:"courses,omitempty"`
 	Writeups []string `json:"writeups,omitempty" yaml:"writeups,omitempty"`
 	Advisory string   `json:"advisory,omitempty" yaml:"-"`
+	// Extra holds front matter keys not defined above, so they survive a rewrite
+	Extra map[string]interface{} `json:"-" yaml:",inline"`
+
+	// node is the parsed YAML front matter, used to keep comments,
+	// key order and styles when compiling
+	node *yaml.Node
 }
 
 type Researcher struct {
@@ -20,4 +28,10 @@ type Researcher struct {
 	Bugcrowd    string   `json:"bugcrowd" yaml:"bugcrowd,omitempty"`
 	CVEs        []string `json:"cves" yaml:"cves"`
 	Bio         string   `json:"bio" yaml:"-"`
+	// Extra holds front matter keys not defined above, so they survive a rewrite
+	Extra map[string]interface{} `json:"-" yaml:",inline"`
+
+	// node is the parsed YAML front matter, used to keep comments,
+	// key order and styles when compiling
+	node *yaml.Node
 }
@@ -0,0 +1,32 @@
+package cvebaser
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+// Version is the cvebaser release version
+const Version = "0.1.0"
+
+// BuildVersion identifies the running build, so that lint caches written by one
+// build aren't reused by another. Release builds are identified by module version
+// and checksum. Development builds, including those with uncommitted changes,
+// are identified by the size and modification time of the executable.
+// Falls back to Version.
+func BuildVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" && !strings.HasSuffix(bi.Main.Version, "+dirty") {
+		return fmt.Sprintf("%s %s", bi.Main.Version, bi.Main.Sum)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return Version
+	}
+	fi, err := os.Stat(exe)
+	if err != nil {
+		return Version
+	}
+	return fmt.Sprintf("%s devel %d %d", Version, fi.Size(), fi.ModTime().UnixNano())
+}
@@ -0,0 +1,13 @@
+package cvebaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildVersion(t *testing.T) {
+	v := BuildVersion()
+	assert.NotEmpty(t, v)
+	assert.Equal(t, v, BuildVersion())
+}